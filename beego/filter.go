@@ -1,7 +1,8 @@
 package beego
 
 import (
-	goctx "context"
+	"fmt"
+	"net/http"
 
 	"github.com/airbrake/gobrake/v4"
 
@@ -18,28 +19,116 @@ func beforeExecFunc() func(c *context.Context) {
 			return
 		}
 
-		_, metric := gobrake.NewRouteMetric(goctx.TODO(), c.Input.Method(), routerPattern)
+		ctx, metric := gobrake.NewRouteMetric(
+			c.Request.Context(), c.Input.Method(), routerPattern)
+		ctx = gobrake.ContextWithRequest(ctx, c.Request)
+		c.Request = c.Request.WithContext(ctx)
 		c.Input.SetData(abMetricKey, metric)
 	}
 }
 
 func afterExecFunc(notifier *gobrake.Notifier) func(c *context.Context) {
 	return func(c *context.Context) {
-		metric, ok := c.Input.GetData(abMetricKey).(*gobrake.RouteMetric)
-		if !ok {
+		status := responseStatus(c)
+		if status >= 500 {
+			notifyStatus(notifier, c, status)
+		}
+		notifyRoute(notifier, c, status)
+	}
+}
+
+// recoverFunc reports panics and responses with 5xx status codes that
+// were aborted with Controller.Abort, and passes the panic to next.
+func recoverFunc(notifier *gobrake.Notifier, next func(*context.Context)) func(c *context.Context) {
+	return func(c *context.Context) {
+		v := recover()
+		if v == nil {
 			return
 		}
 
-		metric.StatusCode = c.Output.Status
-		if metric.StatusCode == 0 {
-			metric.StatusCode = 200
+		status := responseStatus(c)
+		if isAbort(v) {
+			if status >= 500 {
+				notifyStatus(notifier, c, status)
+			}
+		} else {
+			// Skip this function and runtime.gopanic.
+			notice := notifier.Notice(v, c.Request, 2)
+			notice.Context["severity"] = "critical"
+			notifyNotice(notifier, c, notice)
+			if status < 500 {
+				status = http.StatusInternalServerError
+			}
 		}
+		notifyRoute(notifier, c, status)
+
+		if next == nil {
+			panic(v)
+		}
+		func() {
+			defer next(c)
+			panic(v)
+		}()
+	}
+}
+
+// isAbort reports whether the panic was caused by Controller.Abort or
+// Controller.StopRun. Abort panics with the code when there is an error
+// handler for it.
+func isAbort(v interface{}) bool {
+	if v == beego.ErrAbort {
+		return true
+	}
+	if s, ok := v.(string); ok {
+		_, ok := beego.ErrorMaps[s]
+		return ok
+	}
+	return false
+}
+
+func responseStatus(c *context.Context) int {
+	if c.Output.Status != 0 {
+		return c.Output.Status
+	}
+	if c.ResponseWriter.Status != 0 {
+		return c.ResponseWriter.Status
+	}
+	return http.StatusOK
+}
 
-		_ = notifier.Routes.Notify(goctx.TODO(), metric)
+func notifyStatus(notifier *gobrake.Notifier, c *context.Context, status int) {
+	err := fmt.Errorf("%s %s: %d %s",
+		c.Input.Method(), c.Input.URL(), status, http.StatusText(status))
+	notice := notifier.Notice(err, c.Request, 2)
+	notifyNotice(notifier, c, notice)
+}
+
+func notifyNotice(notifier *gobrake.Notifier, c *context.Context, notice *gobrake.Notice) {
+	if route, ok := c.Input.GetData("RouterPattern").(string); ok {
+		notice.Context["route"] = route
+	}
+	notifier.Notify(notice, c.Request)
+}
+
+func notifyRoute(notifier *gobrake.Notifier, c *context.Context, status int) {
+	metric, ok := c.Input.GetData(abMetricKey).(*gobrake.RouteMetric)
+	if !ok {
+		return
 	}
+	// Don't report the route twice.
+	c.Input.SetData(abMetricKey, nil)
+
+	metric.StatusCode = status
+	metric.ContentType = c.ResponseWriter.Header().Get("Content-Type")
+	_ = notifier.Routes.Notify(c.Request.Context(), metric)
 }
 
+// InsertAirbrakeFilters adds filters that report route stats and responses
+// with 5xx status codes, and wraps beego.BConfig.RecoverFunc to report
+// panics. The route metric and the request are stored in the request
+// context, so controllers can start spans with gobrake.ContextRouteMetric.
 func InsertAirbrakeFilters(notifier *gobrake.Notifier) {
 	beego.InsertFilter("*", beego.BeforeExec, beforeExecFunc(), false)
 	beego.InsertFilter("*", beego.AfterExec, afterExecFunc(notifier), false)
+	beego.BConfig.RecoverFunc = recoverFunc(notifier, beego.BConfig.RecoverFunc)
 }
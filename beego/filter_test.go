@@ -0,0 +1,183 @@
+package beego_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	beegobrake "github.com/airbrake/gobrake/v4/beego"
+
+	"github.com/astaxie/beego"
+)
+
+func TestBeego(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "beego")
+}
+
+var (
+	mu          sync.Mutex
+	sentNotices []*gobrake.Notice
+	metric      *gobrake.RouteMetric
+	reqMetric   *gobrake.RouteMetric
+)
+
+type testController struct {
+	beego.Controller
+}
+
+func (c *testController) Get() {
+	reqMetric = gobrake.ContextRouteMetric(c.Ctx.Request.Context())
+
+	switch c.Ctx.Input.Param(":action") {
+	case "panic":
+		panic("boom")
+	case "abort":
+		c.Abort("503")
+	case "custom-abort":
+		c.CustomAbort(http.StatusGatewayTimeout, "timeout")
+	case "fail":
+		c.Ctx.Output.SetStatus(http.StatusBadGateway)
+		c.Ctx.WriteString("bad gateway")
+	default:
+		c.Data["json"] = map[string]bool{"ok": true}
+		c.ServeJSON()
+	}
+}
+
+var _ = Describe("InsertAirbrakeFilters", func() {
+	var notifier *gobrake.Notifier
+
+	BeforeSuite(func() {
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotices = append(sentNotices, notice)
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			metric = m
+			return nil
+		})
+
+		beego.BConfig.RunMode = beego.PROD
+		beego.BConfig.Log.AccessLogs = false
+		beego.BeeLogger.DelLogger("console")
+		beego.ErrorHandler("503", func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("unavailable"))
+		})
+		beego.Router("/test/:action", &testController{})
+		beegobrake.InsertAirbrakeFilters(notifier)
+	})
+
+	AfterSuite(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	BeforeEach(func() {
+		mu.Lock()
+		sentNotices = nil
+		mu.Unlock()
+		metric = nil
+		reqMetric = nil
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		beego.BeeApp.Handlers.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		notifier.Flush()
+		return w
+	}
+
+	notices := func() []*gobrake.Notice {
+		mu.Lock()
+		defer mu.Unlock()
+		return sentNotices
+	}
+
+	It("reports route stats with context and content type", func() {
+		get("/test/ok")
+
+		Expect(metric).NotTo(BeNil())
+		Expect(reqMetric).To(BeIdenticalTo(metric))
+		Expect(metric.Route).To(Equal("/test/:action"))
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+		Expect(metric.ContentType).To(Equal("application/json; charset=utf-8"))
+		Expect(notices()).To(BeEmpty())
+	})
+
+	It("reports panics", func() {
+		w := get("/test/panic")
+		Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("boom"))
+		Expect(notice.Errors[0].Backtrace[0].File).To(HaveSuffix("filter_test.go"))
+		Expect(notice.Context["severity"]).To(Equal("critical"))
+		Expect(notice.Context["route"]).To(Equal("/test/:action"))
+		Expect(notice.Context["url"]).To(Equal("/test/panic"))
+	})
+
+	It("reports aborts with 5xx status codes", func() {
+		w := get("/test/abort")
+		Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(metric.StatusCode).To(Equal(http.StatusServiceUnavailable))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("GET /test/abort: 503 Service Unavailable"))
+		Expect(notice.Context["route"]).To(Equal("/test/:action"))
+	})
+
+	It("reports custom aborts with 5xx status codes", func() {
+		w := get("/test/custom-abort")
+		Expect(w.Code).To(Equal(http.StatusGatewayTimeout))
+		Expect(metric.StatusCode).To(Equal(http.StatusGatewayTimeout))
+
+		Expect(notices()).To(HaveLen(1))
+		Expect(notices()[0].Errors[0].Message).To(
+			Equal("GET /test/custom-abort: 504 Gateway Timeout"))
+	})
+
+	It("reports 5xx responses", func() {
+		get("/test/fail")
+		Expect(metric.StatusCode).To(Equal(http.StatusBadGateway))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("GET /test/fail: 502 Bad Gateway"))
+		Expect(notice.Context["httpMethod"]).To(Equal("GET"))
+	})
+})
@@ -0,0 +1,114 @@
+package gobrake
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	buildInfoOnce sync.Once
+	buildInfo     *debug.BuildInfo
+)
+
+// getBuildInfo returns build information embedded into the binary or nil
+// when the binary is built without module support.
+func getBuildInfo() *debug.BuildInfo {
+	buildInfoOnce.Do(func() {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			buildInfo = bi
+		}
+	})
+	return buildInfo
+}
+
+func buildSetting(bi *debug.BuildInfo, key string) string {
+	for _, s := range bi.Settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// buildVCSRevision returns the VCS revision stamped by the go command.
+func buildVCSRevision() string {
+	bi := getBuildInfo()
+	if bi == nil {
+		return ""
+	}
+	return buildSetting(bi, "vcs.revision")
+}
+
+// addBuildInfoContext adds the main module and VCS information to context.
+func addBuildInfoContext(context map[string]interface{}) {
+	bi := getBuildInfo()
+	if bi == nil {
+		return
+	}
+
+	if bi.Main.Path != "" {
+		context["mainModule"] = map[string]interface{}{
+			"path":    bi.Main.Path,
+			"version": bi.Main.Version,
+		}
+	}
+	if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		context["version"] = bi.Main.Version
+	}
+
+	vcs := make(map[string]interface{})
+	if s := buildSetting(bi, "vcs"); s != "" {
+		vcs["system"] = s
+	}
+	if s := buildSetting(bi, "vcs.revision"); s != "" {
+		vcs["revision"] = s
+	}
+	if s := buildSetting(bi, "vcs.time"); s != "" {
+		vcs["time"] = s
+	}
+	if s := buildSetting(bi, "vcs.modified"); s != "" {
+		vcs["modified"] = s == "true"
+	}
+	if len(vcs) > 0 {
+		context["vcs"] = vcs
+	}
+}
+
+var (
+	buildDepsOnce sync.Once
+	buildDeps     map[string]interface{}
+)
+
+// getBuildDependencies returns versions of modules the binary is built with.
+func getBuildDependencies() map[string]interface{} {
+	buildDepsOnce.Do(func() {
+		bi := getBuildInfo()
+		if bi == nil {
+			return
+		}
+
+		buildDeps = make(map[string]interface{}, len(bi.Deps))
+		for _, dep := range bi.Deps {
+			version := dep.Version
+			if dep.Replace != nil {
+				if dep.Replace.Version != "" {
+					version = dep.Replace.Version
+				} else {
+					version = dep.Replace.Path
+				}
+			}
+			buildDeps[dep.Path] = version
+		}
+	})
+	return buildDeps
+}
+
+func buildInfoRevisionFilter(notice *Notice) *Notice {
+	if notice.Context["revision"] != nil {
+		return notice
+	}
+	if rev := buildVCSRevision(); rev != "" {
+		notice.Context["revision"] = rev
+	}
+	return notice
+}
@@ -0,0 +1,76 @@
+package gobrake_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+var _ = Describe("build info", func() {
+	var notifier *gobrake.Notifier
+	var sentNotice *gobrake.Notice
+	var opt *gobrake.NotifierOptions
+
+	BeforeEach(func() {
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			sentNotice = new(gobrake.Notice)
+			err = json.Unmarshal(b, sentNotice)
+			Expect(err).To(BeNil())
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		configServer := newConfigServer()
+
+		opt = &gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: configServer.URL,
+		}
+	})
+
+	JustBeforeEach(func() {
+		notifier = gobrake.NewNotifierWithOptions(opt)
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("reports main module", func() {
+		notifier.Notify("hello", nil)
+		notifier.Flush()
+
+		Expect(sentNotice.Context["mainModule"]).To(Equal(map[string]interface{}{
+			"path":    "github.com/airbrake/gobrake/v4",
+			"version": "(devel)",
+		}))
+		Expect(sentNotice.Context["dependencies"]).To(BeNil())
+	})
+
+	Context("ReportDependencies", func() {
+		BeforeEach(func() {
+			opt.ReportDependencies = true
+		})
+
+		It("reports dependency versions", func() {
+			notifier.Notify("hello", nil)
+			notifier.Flush()
+
+			deps, ok := sentNotice.Context["dependencies"].(map[string]interface{})
+			Expect(ok).To(BeTrue())
+			Expect(deps["github.com/onsi/ginkgo"]).To(Equal("v1.8.0"))
+		})
+	})
+})
@@ -0,0 +1,65 @@
+package gobrake
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// CheckCredentials verifies that the project id and key are accepted by the
+// notices API. No read-only endpoint accepts project keys, so it sends an
+// empty notice. The API checks credentials before the payload and rejects
+// the empty notice with 400 or 422, so normally no notice is created. A 2xx
+// response also means that the credentials are valid, but a notice may have
+// been created, which is logged.
+func (n *Notifier) CheckCredentials(c context.Context) error {
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/api/v3/projects/%d/notices",
+			n.opt.Host, n.opt.ProjectId),
+		bytes.NewBufferString("{}"),
+	)
+	if err != nil {
+		return err
+	}
+	if c != nil {
+		req = req.WithContext(c)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+n.opt.ProjectKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := n.opt.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		logger.Printf("CheckCredentials: the empty notice was accepted, "+
+			"a notice may have been created in project %d", n.opt.ProjectId)
+		return nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		// Credentials are valid, the payload is not.
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errUnauthorized
+	case http.StatusNotFound:
+		return errProjectNotFound
+	case httpStatusTooManyRequests:
+		return errIPRateLimited
+	case httpEnhanceYourCalm:
+		return errAccountRateLimited
+	}
+
+	return fmt.Errorf("got unexpected response status=%q", resp.Status)
+}
+
+// FetchRemoteConfig fetches the remote config of the project.
+func (n *Notifier) FetchRemoteConfig() (*RemoteConfigJSON, error) {
+	return n.remoteConfig.fetch()
+}
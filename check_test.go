@@ -0,0 +1,142 @@
+package gobrake_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+var _ = Describe("CheckCredentials", func() {
+	var notifier *gobrake.Notifier
+	var checkReq *http.Request
+	var checkBody string
+	var status int
+
+	BeforeEach(func() {
+		status = http.StatusBadRequest
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			checkReq = req
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+			checkBody = string(b)
+
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message":"invalid notice"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		configServer := newConfigServer()
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: configServer.URL,
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("accepts rejected payload", func() {
+		err := notifier.CheckCredentials(context.Background())
+		Expect(err).To(BeNil())
+
+		Expect(checkReq.Method).To(Equal("POST"))
+		Expect(checkReq.URL.Path).To(Equal("/api/v3/projects/1/notices"))
+		Expect(checkReq.Header.Get("Authorization")).To(Equal("Bearer key"))
+		Expect(checkBody).To(Equal("{}"))
+	})
+
+	It("accepts created notice and logs it", func() {
+		status = http.StatusCreated
+		origLogger := gobrake.GetLogger()
+		defer gobrake.SetLogger(origLogger)
+		var buf bytes.Buffer
+		gobrake.SetLogger(log.New(&buf, "", 0))
+
+		err := notifier.CheckCredentials(context.Background())
+		Expect(err).To(BeNil())
+		Expect(buf.String()).To(ContainSubstring("a notice may have been created in project 1"))
+	})
+
+	It("returns error on invalid project id or key", func() {
+		status = http.StatusUnauthorized
+
+		err := notifier.CheckCredentials(context.Background())
+		Expect(err).To(MatchError("gobrake: unauthorized: invalid project id or key"))
+	})
+
+	It("returns error on unknown project", func() {
+		status = http.StatusNotFound
+
+		err := notifier.CheckCredentials(context.Background())
+		Expect(err).To(MatchError("gobrake: project not found: check host and project id"))
+	})
+
+	It("returns error on rate limit", func() {
+		status = http.StatusTooManyRequests
+
+		err := notifier.CheckCredentials(context.Background())
+		Expect(err).To(MatchError("gobrake: IP is rate limited"))
+	})
+})
+
+var _ = Describe("FetchRemoteConfig", func() {
+	It("fetches remote config", func() {
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(`{"project_id":1,"poll_sec":60,` +
+				`"settings":[{"name":"apm","enabled":false}]}`))
+		}
+		configServer := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier := gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			RemoteConfigHost: configServer.URL,
+		})
+		defer notifier.Close()
+
+		rc, err := notifier.FetchRemoteConfig()
+		Expect(err).To(BeNil())
+		Expect(rc.PollSec).To(Equal(int64(60)))
+		Expect(rc.RemoteSettings).To(HaveLen(1))
+		Expect(rc.RemoteSettings[0].Name).To(Equal("apm"))
+	})
+
+	It("can be called while the notifier polls remote config", func() {
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(`{"project_id":1,"poll_sec":1}`))
+		}
+		configServer := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier := gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			RemoteConfigHost: configServer.URL,
+		})
+		defer notifier.Close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := notifier.FetchRemoteConfig()
+				Expect(err).To(BeNil())
+			}()
+		}
+		wg.Wait()
+	})
+})
@@ -0,0 +1,27 @@
+module github.com/airbrake/gobrake/v4/chi
+
+go 1.21
+
+require (
+	github.com/airbrake/gobrake/v4 v4.3.0
+	github.com/go-chi/chi/v5 v5.0.12
+	github.com/onsi/ginkgo v1.8.0
+	github.com/onsi/gomega v1.5.0
+)
+
+require (
+	github.com/caio/go-tdigest v3.1.0+incompatible // indirect
+	github.com/hpcloud/tail v1.0.0 // indirect
+	github.com/jonboulle/clockwork v0.1.1-0.20190114141812-62fb9bc030d1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/net v0.0.0-20190503192946-f4e77d36d62c // indirect
+	golang.org/x/sys v0.0.0-20190422165155-953cdadca894 // indirect
+	golang.org/x/text v0.3.0 // indirect
+	gopkg.in/fsnotify.v1 v1.4.7 // indirect
+	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
+
+// Builds against the working tree during development. Replace directives
+// are ignored by dependents, which use the required release.
+replace github.com/airbrake/gobrake/v4 => ../
@@ -0,0 +1,35 @@
+// Package chi provides a chi middleware that reports panics to Airbrake and
+// collects route stats.
+package chi
+
+import (
+	"net/http"
+
+	"github.com/airbrake/gobrake/v4"
+	httpbrake "github.com/airbrake/gobrake/v4/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewMiddleware returns a middleware for chi.Router.Use that reports route
+// stats using chi route patterns, e.g. /users/{id}, and reports panics as
+// critical notices. The route metric and the request are stored in the
+// request context. Panics are answered with 500 Internal Server Error.
+func NewMiddleware(notifier *gobrake.Notifier) func(http.Handler) http.Handler {
+	return httpbrake.New(notifier, &httpbrake.Options{
+		RouteName: RouteName,
+	}).Handler
+}
+
+// RouteName returns the chi route pattern of the request. Requests that
+// don't match any route are reported as UNKNOWN.
+func RouteName(req *http.Request) string {
+	rctx := chi.RouteContext(req.Context())
+	if rctx == nil {
+		return "UNKNOWN"
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+	return "UNKNOWN"
+}
@@ -0,0 +1,126 @@
+package chi_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	chibrake "github.com/airbrake/gobrake/v4/chi"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestChi(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "chi")
+}
+
+var _ = Describe("NewMiddleware", func() {
+	var notifier *gobrake.Notifier
+	var router chi.Router
+
+	var mu sync.Mutex
+	var sentNotice *gobrake.Notice
+	var metric *gobrake.RouteMetric
+
+	BeforeEach(func() {
+		sentNotice = nil
+		metric = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotice = notice
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			metric = m
+			return nil
+		})
+
+		router = chi.NewRouter()
+		router.Use(chibrake.NewMiddleware(notifier))
+		router.Route("/users", func(r chi.Router) {
+			r.Get("/{id}", func(w http.ResponseWriter, req *http.Request) {
+				Expect(gobrake.ContextRouteMetric(req.Context())).NotTo(BeNil())
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusAccepted)
+			})
+		})
+		router.Get("/panic", func(w http.ResponseWriter, req *http.Request) {
+			panic("boom")
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		notifier.Flush()
+		return rec
+	}
+
+	It("reports route pattern, status and content type", func() {
+		rec := serve("/users/123")
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+
+		Expect(metric).NotTo(BeNil())
+		Expect(metric.Method).To(Equal("GET"))
+		Expect(metric.Route).To(Equal("/users/{id}"))
+		Expect(metric.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(metric.ContentType).To(Equal("application/json"))
+	})
+
+	It("reports unmatched requests as UNKNOWN", func() {
+		rec := serve("/missing")
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+		Expect(metric.Route).To(Equal("UNKNOWN"))
+		Expect(metric.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("reports panics", func() {
+		rec := serve("/panic")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotice).NotTo(BeNil())
+		Expect(sentNotice.Errors[0].Message).To(Equal("boom"))
+		Expect(sentNotice.Context["severity"]).To(Equal("critical"))
+		Expect(sentNotice.Context["route"]).To(Equal("/panic"))
+	})
+})
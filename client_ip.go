@@ -0,0 +1,173 @@
+package gobrake
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// defaultTrustedProxies are loopback and private networks where load
+// balancers and reverse proxies usually live.
+var defaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+var defaultClientIP = newClientIPResolver(defaultTrustedProxies)
+
+type clientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// newClientIPResolver returns a function that resolves the client IP address
+// of a request. Forwarding headers are only honored when they are set by one
+// of the trusted proxies, which are IP addresses or CIDR ranges. Invalid
+// entries are logged and ignored.
+func newClientIPResolver(trustedProxies []string) func(*http.Request) string {
+	r := new(clientIPResolver)
+	for _, s := range trustedProxies {
+		ipNet, err := parseIPNet(s)
+		if err != nil {
+			logger.Printf("invalid trusted proxy=%q: %s", s, err)
+			continue
+		}
+		r.trusted = append(r.trusted, ipNet)
+	}
+	return r.clientIP
+}
+
+func parseIPNet(s string) (*net.IPNet, error) {
+	if strings.IndexByte(s, '/') == -1 {
+		if ip := net.ParseIP(s); ip != nil {
+			if ip4 := ip.To4(); ip4 != nil {
+				return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+			}
+			return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+		}
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	return ipNet, err
+}
+
+func (r *clientIPResolver) isTrusted(ip net.IP) bool {
+	for _, ipNet := range r.trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *clientIPResolver) clientIP(req *http.Request) string {
+	remoteIP := parseIP(req.RemoteAddr)
+	if remoteIP == nil {
+		return req.RemoteAddr
+	}
+	if !r.isTrusted(remoteIP) {
+		return remoteIP.String()
+	}
+
+	hops := forwardedFor(req.Header)
+	if hops == nil {
+		hops = xForwardedFor(req.Header)
+	}
+	if hops == nil {
+		if ip := parseIP(req.Header.Get("X-Real-Ip")); ip != nil {
+			return ip.String()
+		}
+		return remoteIP.String()
+	}
+
+	// Walk the chain from the right: every hop appended by a trusted proxy
+	// can be skipped, the first untrusted one is the client.
+	client := remoteIP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseIP(hops[i])
+		if ip == nil {
+			// The proxy has obfuscated the client, e.g. for=unknown.
+			break
+		}
+		client = ip
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// parseIP parses an IP address that is optionally followed by a port.
+// IPv6 addresses may be enclosed in brackets.
+func parseIP(s string) net.IP {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if ind := strings.IndexByte(s, '%'); ind != -1 {
+		// Drop IPv6 zone.
+		s = s[:ind]
+	}
+	return net.ParseIP(s)
+}
+
+func xForwardedFor(header http.Header) []string {
+	var hops []string
+	for _, v := range header["X-Forwarded-For"] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				hops = append(hops, s)
+			}
+		}
+	}
+	return hops
+}
+
+// forwardedFor returns "for" parameters of the RFC 7239 Forwarded header.
+func forwardedFor(header http.Header) []string {
+	var hops []string
+	for _, v := range header["Forwarded"] {
+		for _, elem := range splitQuoted(v, ',') {
+			for _, pair := range splitQuoted(elem, ';') {
+				ind := strings.IndexByte(pair, '=')
+				if ind == -1 {
+					continue
+				}
+				key := strings.TrimSpace(pair[:ind])
+				if !strings.EqualFold(key, "for") {
+					continue
+				}
+				value := strings.TrimSpace(pair[ind+1:])
+				value = strings.Trim(value, `"`)
+				hops = append(hops, value)
+			}
+		}
+	}
+	return hops
+}
+
+// splitQuoted splits s by sep ignoring separators inside quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	var quoted bool
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if quoted {
+				i++
+			}
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
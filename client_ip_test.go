@@ -0,0 +1,71 @@
+package gobrake
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("clientIP", func() {
+	clientIP := newClientIPResolver([]string{"10.0.0.0/8", "2001:db8::/32"})
+
+	newRequest := func(remoteAddr string, header http.Header) *http.Request {
+		return &http.Request{
+			RemoteAddr: remoteAddr,
+			Header:     header,
+		}
+	}
+
+	It("uses remote address of untrusted peers", func() {
+		req := newRequest("203.0.113.7:5555", http.Header{
+			"X-Forwarded-For": {"1.1.1.1"},
+			"X-Real-Ip":       {"2.2.2.2"},
+		})
+		Expect(clientIP(req)).To(Equal("203.0.113.7"))
+	})
+
+	It("handles IPv6 remote addresses", func() {
+		Expect(clientIP(newRequest("[2001:db9::1]:443", nil))).To(Equal("2001:db9::1"))
+		Expect(clientIP(newRequest("[fe80::1%eth0]:443", nil))).To(Equal("fe80::1"))
+	})
+
+	It("walks X-Forwarded-For from the right", func() {
+		req := newRequest("10.0.0.1:5555", http.Header{
+			"X-Forwarded-For": {"6.6.6.6, 203.0.113.7", "10.0.0.2:8080"},
+		})
+		Expect(clientIP(req)).To(Equal("203.0.113.7"))
+	})
+
+	It("returns the leftmost address when all hops are trusted", func() {
+		req := newRequest("10.0.0.1:5555", http.Header{
+			"X-Forwarded-For": {"10.1.1.1, 10.2.2.2"},
+		})
+		Expect(clientIP(req)).To(Equal("10.1.1.1"))
+	})
+
+	It("parses RFC 7239 Forwarded header", func() {
+		req := newRequest("[2001:db8::1]:443", http.Header{
+			"Forwarded": {
+				`for=6.6.6.6;proto=http, for="[2001:db9:cafe::17]:4711";by=x`,
+				`For="[2001:db8::2]"`,
+			},
+			"X-Forwarded-For": {"7.7.7.7"},
+		})
+		Expect(clientIP(req)).To(Equal("2001:db9:cafe::17"))
+	})
+
+	It("stops at obfuscated identifiers", func() {
+		req := newRequest("10.0.0.1:5555", http.Header{
+			"Forwarded": {"for=6.6.6.6, for=unknown, for=10.0.0.2"},
+		})
+		Expect(clientIP(req)).To(Equal("10.0.0.2"))
+	})
+
+	It("uses X-Real-Ip set by trusted proxies", func() {
+		req := newRequest("10.0.0.1:5555", http.Header{
+			"X-Real-Ip": {"203.0.113.7"},
+		})
+		Expect(clientIP(req)).To(Equal("203.0.113.7"))
+	})
+})
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	opt := notifierFlags(fs)
+	timeout := fs.Duration("timeout", 10*time.Second, "request timeout")
+	_ = fs.Parse(args)
+
+	if err := validateOptions(opt); err != nil {
+		return err
+	}
+
+	notifier := gobrake.NewNotifierWithOptions(opt)
+	defer notifier.Close()
+
+	c, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := notifier.CheckCredentials(c); err != nil {
+		return err
+	}
+	fmt.Printf("credentials: ok (project %d at %s)\n", opt.ProjectId, opt.Host)
+
+	rc, err := notifier.FetchRemoteConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("remote config: ok (poll every %ds)\n", rc.PollSec)
+	for _, s := range rc.RemoteSettings {
+		state := "disabled"
+		if s.Enabled {
+			state = "enabled"
+		}
+		if s.Endpoint != "" {
+			fmt.Printf("  %s: %s (%s)\n", s.Name, state, s.Endpoint)
+		} else {
+			fmt.Printf("  %s: %s\n", s.Name, state)
+		}
+	}
+	return nil
+}
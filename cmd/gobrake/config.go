@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	opt := notifierFlags(fs)
+	_ = fs.Parse(args)
+
+	// The notifier fills in defaults, e.g. hosts and revision.
+	notifier := gobrake.NewNotifierWithOptions(opt)
+	notifier.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	printOptions(w, opt)
+	return w.Flush()
+}
+
+func printOptions(w *tabwriter.Writer, opt *gobrake.NotifierOptions) {
+	v := reflect.ValueOf(opt).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		switch f.Type.Kind() {
+		case reflect.Func, reflect.Ptr:
+			// Hooks and clients can't be printed meaningfully.
+			continue
+		}
+
+		value := fmt.Sprint(v.Field(i).Interface())
+		if f.Name == "ProjectKey" {
+			value = redactKey(value)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", f.Name, value)
+	}
+}
+
+// redactKey hides all but the last 4 characters of the key.
+func redactKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return strings.Repeat("*", len(key)-4) + key[len(key)-4:]
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+func runDeploy(args []string) error {
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	opt := notifierFlags(fs)
+
+	var d gobrake.Deploy
+	fs.StringVar(&d.Username, "username", "",
+		"user who deployed (default is the author of the last git checkout)")
+	fs.StringVar(&d.Repository, "repository", "",
+		"repository URL (default is the origin remote)")
+	fs.StringVar(&d.Revision, "revision", "",
+		"deployed revision (default is SOURCE_VERSION or git HEAD)")
+	fs.StringVar(&d.Version, "version", "", "deployed version")
+	timeout := fs.Duration("timeout", 10*time.Second, "request timeout")
+	_ = fs.Parse(args)
+
+	if err := validateOptions(opt); err != nil {
+		return err
+	}
+	if opt.Environment == "" {
+		return fmt.Errorf("environment is required")
+	}
+
+	notifier := gobrake.NewNotifierWithOptions(opt)
+	defer notifier.Close()
+
+	c, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := notifier.NotifyDeploy(c, d); err != nil {
+		return err
+	}
+
+	fmt.Printf("deploy to %s recorded\n", opt.Environment)
+	return nil
+}
@@ -0,0 +1,93 @@
+// Command gobrake interacts with Airbrake from the command line, e.g. to
+// record deploys from release pipelines or to test notifier configuration.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+const usage = `Usage: gobrake <command> [flags]
+
+Commands:
+  deploy    notify Airbrake about a deploy
+  notify    send a test notice, e.g. "gobrake notify -severity info hello"
+  check     verify the project id and key and fetch the remote config
+  config    print the effective notifier options
+
+Run "gobrake <command> -h" for the command flags. Flags default to the
+AIRBRAKE_PROJECT_ID, AIRBRAKE_PROJECT_KEY, AIRBRAKE_HOST and
+AIRBRAKE_ENVIRONMENT environment variables.
+`
+
+type command func(args []string) error
+
+var commands = map[string]command{
+	"deploy": runDeploy,
+	"notify": runNotify,
+	"check":  runCheck,
+	"config": runConfig,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gobrake: unknown command %q\n\n%s", name, usage)
+		os.Exit(2)
+	}
+
+	// Errors are reported by the commands.
+	gobrake.SetLogger(log.New(ioutil.Discard, "", 0))
+
+	if err := cmd(os.Args[2:]); err != nil {
+		msg := strings.TrimPrefix(err.Error(), "gobrake: ")
+		fmt.Fprintf(os.Stderr, "gobrake %s: %s\n", name, msg)
+		os.Exit(1)
+	}
+}
+
+// notifierFlags defines flags common to all commands.
+func notifierFlags(fs *flag.FlagSet) *gobrake.NotifierOptions {
+	opt := new(gobrake.NotifierOptions)
+
+	projectID, _ := strconv.ParseInt(os.Getenv("AIRBRAKE_PROJECT_ID"), 10, 64)
+	fs.Int64Var(&opt.ProjectId, "project-id", projectID,
+		"Airbrake project id (AIRBRAKE_PROJECT_ID)")
+	fs.StringVar(&opt.ProjectKey, "project-key", os.Getenv("AIRBRAKE_PROJECT_KEY"),
+		"Airbrake project key (AIRBRAKE_PROJECT_KEY)")
+	fs.StringVar(&opt.Host, "host", os.Getenv("AIRBRAKE_HOST"),
+		"Airbrake host (AIRBRAKE_HOST)")
+	fs.StringVar(&opt.Environment, "environment", os.Getenv("AIRBRAKE_ENVIRONMENT"),
+		"environment, e.g. production (AIRBRAKE_ENVIRONMENT)")
+
+	return opt
+}
+
+func validateOptions(opt *gobrake.NotifierOptions) error {
+	if opt.ProjectId == 0 {
+		return fmt.Errorf("project id is required")
+	}
+	if opt.ProjectKey == "" {
+		return fmt.Errorf("project key is required")
+	}
+	return nil
+}
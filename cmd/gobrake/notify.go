@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+func runNotify(args []string) error {
+	fs := flag.NewFlagSet("notify", flag.ExitOnError)
+	opt := notifierFlags(fs)
+	severity := fs.String("severity", "error",
+		"notice severity: debug, info, notice, warning, error or critical")
+	_ = fs.Parse(args)
+
+	if err := validateOptions(opt); err != nil {
+		return err
+	}
+
+	msg := strings.Join(fs.Args(), " ")
+	if msg == "" {
+		msg = "gobrake: test notice"
+	}
+
+	notifier := gobrake.NewNotifierWithOptions(opt)
+	defer notifier.Close()
+
+	notice := notifier.Notice(msg, nil, 0)
+	notice.Context["severity"] = *severity
+	notice.Context["component"] = "gobrake-cli"
+
+	id, err := notifier.SendNotice(notice)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("notice sent: id=%s\n", id)
+	return nil
+}
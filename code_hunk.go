@@ -2,73 +2,221 @@ package gobrake
 
 import (
 	"bufio"
-	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"strconv"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/airbrake/gobrake/v4/internal/lrucache"
 )
 
-var cache = lrucache.New(1000)
+const (
+	defaultCodeHunkLines      = 2
+	defaultCodeHunkMaxLineLen = 512
+)
 
-func getCode(file string, line int) (map[int]string, error) {
-	cacheKey := file + strconv.Itoa(line)
+const (
+	// Source files are re-read at least that often.
+	codeCacheTTL = 5 * time.Minute
+	// Maximum total size of cached source files.
+	codeCacheMaxSize = 16 << 20
+	// Maximum number of cached files, including failed reads.
+	codeCacheMaxLen = 4096
+	// Size accounted for a cached failed read.
+	codeCacheErrSize = 256
+)
 
-	v, ok := cache.Get(cacheKey)
-	if ok {
-		switch v := v.(type) {
-		case error:
-			return nil, v
-		case map[int]string:
-			return v, nil
-		default:
-			return nil, fmt.Errorf("unsupported type=%T", v)
-		}
+// sourceFile is a cached source file. Lines are truncated to maxLineLen.
+type sourceFile struct {
+	lines   []string
+	size    int64
+	modTime time.Time
+	err     error
+}
+
+type codeHunks struct {
+	fsys       fs.FS
+	nlines     int
+	maxLineLen int
+
+	cache *lrucache.Cache
+}
+
+func newCodeHunks(opt *NotifierOptions) *codeHunks {
+	nlines := opt.CodeHunkLines
+	if nlines < 0 {
+		nlines = 0
+	}
+	return &codeHunks{
+		fsys:       opt.SourceFS,
+		nlines:     nlines,
+		maxLineLen: opt.CodeHunkMaxLineLen,
+
+		cache: lrucache.NewWithOptions(&lrucache.Options{
+			MaxLen:  codeCacheMaxLen,
+			MaxSize: codeCacheMaxSize,
+			TTL:     codeCacheTTL,
+		}),
 	}
+}
 
-	lines, err := _getCode(file, line)
+func (h *codeHunks) getCode(file string, line int) (map[int]string, error) {
+	f, err := h.getFile(file)
 	if err != nil {
-		cache.Set(cacheKey, err)
 		return nil, err
 	}
 
+	start := line - h.nlines
+	if start < 1 {
+		start = 1
+	}
+	end := line + h.nlines
+	if end > len(f.lines) {
+		end = len(f.lines)
+	}
+
+	lines := make(map[int]string, 2*h.nlines+1)
+	for i := start; i <= end; i++ {
+		lines[i] = f.lines[i-1]
+	}
 	return lines, nil
 }
 
-func _getCode(file string, line int) (map[int]string, error) {
-	const nlines = 2
-	const maxLineLen = 512
+// getFile returns the file from the cache unless it was changed since it
+// was cached. Failures are cached until they expire.
+func (h *codeHunks) getFile(file string) (*sourceFile, error) {
+	var fi fs.FileInfo
+	if v, ok := h.cache.Get(file); ok {
+		f := v.(*sourceFile)
+		if f.err != nil {
+			return nil, f.err
+		}
+
+		var err error
+		fi, err = h.stat(file)
+		if err == nil && fi.Size() == f.size && fi.ModTime().Equal(f.modTime) {
+			return f, nil
+		}
+	}
+
+	f, err := h.readFile(file, fi)
+	if err != nil {
+		h.cache.SetWithSize(file, &sourceFile{err: err}, codeCacheErrSize)
+		return nil, err
+	}
+	h.cache.SetWithSize(file, f, int(f.size))
+	return f, nil
+}
 
-	fd, err := os.Open(file)
+func (h *codeHunks) readFile(file string, fi fs.FileInfo) (*sourceFile, error) {
+	fd, err := h.open(file)
 	if err != nil {
 		return nil, err
 	}
 	defer fd.Close()
 
-	start := line - nlines
-	end := line + nlines
-	scanner := bufio.NewScanner(fd)
+	if fi == nil {
+		fi, err = fd.Stat()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f := &sourceFile{
+		size:    fi.Size(),
+		modTime: fi.ModTime(),
+	}
 
-	var i int
-	lines := make(map[int]string, nlines)
-	for scanner.Scan() {
-		i++
-		if i < start {
-			continue
+	r := bufio.NewReader(fd)
+	for {
+		line, err := r.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(line, "\n")
+			line = strings.TrimSuffix(line, "\r")
+			line = truncateLine(line, h.maxLineLen)
+			f.lines = append(f.lines, line)
 		}
-		if i > end {
+		if err == io.EOF {
 			break
 		}
-		line := scanner.Text()
-		if len(line) > maxLineLen {
-			line = line[:maxLineLen]
+		if err != nil {
+			return nil, err
 		}
-		lines[i] = line
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	return f, nil
+}
+
+// truncateLine truncates the line to at most n bytes without splitting
+// UTF-8 encoded runes.
+func truncateLine(line string, n int) string {
+	if len(line) <= n {
+		return line
+	}
+	for n > 0 && !utf8.RuneStart(line[n]) {
+		n--
 	}
+	return line[:n]
+}
 
-	return lines, nil
+// open opens the file from SourceFS falling back to the local file system.
+func (h *codeHunks) open(file string) (fs.File, error) {
+	if h.fsys != nil {
+		for _, name := range sourcePaths(file) {
+			fd, err := h.fsys.Open(name)
+			if err == nil {
+				return fd, nil
+			}
+		}
+	}
+	return os.Open(file)
+}
+
+func (h *codeHunks) stat(file string) (fs.FileInfo, error) {
+	if h.fsys != nil {
+		for _, name := range sourcePaths(file) {
+			fi, err := fs.Stat(h.fsys, name)
+			if err == nil {
+				return fi, nil
+			}
+		}
+	}
+	return os.Stat(file)
+}
+
+// sourcePaths returns paths of the file relative to the main module root.
+// Builds with -trimpath record files as <module path>/<path in module>.
+// Other builds record absolute paths, which are resolved against the
+// working directory.
+func sourcePaths(file string) []string {
+	file = filepath.ToSlash(file)
+
+	var names []string
+	if bi := getBuildInfo(); bi != nil && bi.Main.Path != "" {
+		if name := strings.TrimPrefix(file, bi.Main.Path+"/"); name != file {
+			names = append(names, name)
+		}
+	}
+	if wd, _ := getDefaultContext()["rootDirectory"].(string); wd != "" {
+		wd = filepath.ToSlash(wd)
+		if name := strings.TrimPrefix(file, wd+"/"); name != file {
+			names = append(names, name)
+		}
+	}
+	// The file system can also be rooted at GOPATH/src or contain a
+	// directory per module.
+	names = append(names, strings.TrimPrefix(file, "/"))
+
+	valid := names[:0]
+	for _, name := range names {
+		name = path.Clean(name)
+		if fs.ValidPath(name) {
+			valid = append(valid, name)
+		}
+	}
+	return valid
 }
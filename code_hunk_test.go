@@ -0,0 +1,235 @@
+package gobrake
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("codeHunks", func() {
+	source := "line1\nline2\nline3\nline4\nline5\nline6\nline7\n"
+	sourceFS := fstest.MapFS{
+		"pkg/app.go": &fstest.MapFile{Data: []byte(source)},
+	}
+
+	newHunks := func(opt *NotifierOptions) *codeHunks {
+		opt.init()
+		return newCodeHunks(opt)
+	}
+
+	It("reads files from -trimpath builds by module path", func() {
+		hunks := newHunks(&NotifierOptions{SourceFS: sourceFS})
+
+		code, err := hunks.getCode("github.com/airbrake/gobrake/v4/pkg/app.go", 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(map[int]string{
+			2: "line2",
+			3: "line3",
+			4: "line4",
+			5: "line5",
+			6: "line6",
+		}))
+	})
+
+	It("reads files by path relative to the working directory", func() {
+		wd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		hunks := newHunks(&NotifierOptions{SourceFS: sourceFS})
+
+		code, err := hunks.getCode(filepath.Join(wd, "pkg", "app.go"), 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(map[int]string{
+			1: "line1",
+			2: "line2",
+			3: "line3",
+		}))
+	})
+
+	It("falls back to the local file system", func() {
+		wd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		hunks := newHunks(&NotifierOptions{SourceFS: sourceFS})
+
+		code, err := hunks.getCode(filepath.Join(wd, "code_hunk_test.go"), 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code[1]).To(Equal("package gobrake"))
+	})
+
+	It("returns not exist error for unknown files", func() {
+		hunks := newHunks(&NotifierOptions{SourceFS: sourceFS})
+
+		_, err := hunks.getCode("github.com/airbrake/gobrake/v4/pkg/missing.go", 1)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("supports custom number of lines and line length", func() {
+		hunks := newHunks(&NotifierOptions{
+			SourceFS:           sourceFS,
+			CodeHunkLines:      1,
+			CodeHunkMaxLineLen: 3,
+		})
+
+		code, err := hunks.getCode("github.com/airbrake/gobrake/v4/pkg/app.go", 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(map[int]string{
+			3: "lin",
+			4: "lin",
+			5: "lin",
+		}))
+	})
+
+	It("supports code hunks without context lines", func() {
+		hunks := newHunks(&NotifierOptions{
+			SourceFS:      sourceFS,
+			CodeHunkLines: -1,
+		})
+
+		code, err := hunks.getCode("github.com/airbrake/gobrake/v4/pkg/app.go", 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(map[int]string{
+			4: "line4",
+		}))
+	})
+
+	It("truncates lines at rune boundaries", func() {
+		hunks := newHunks(&NotifierOptions{
+			SourceFS: fstest.MapFS{
+				"utf8.go": &fstest.MapFile{Data: []byte("// héllo\n")},
+			},
+			CodeHunkMaxLineLen: 5,
+		})
+
+		code, err := hunks.getCode("github.com/airbrake/gobrake/v4/utf8.go", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code[1]).To(Equal("// h"))
+	})
+
+	It("truncates long lines to 512 bytes by default", func() {
+		long := strings.Repeat("x", 1000)
+		hunks := newHunks(&NotifierOptions{
+			SourceFS: fstest.MapFS{
+				"long.go": &fstest.MapFile{Data: []byte(long + "\n")},
+			},
+		})
+
+		code, err := hunks.getCode("github.com/airbrake/gobrake/v4/long.go", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code[1]).To(HaveLen(512))
+	})
+
+	It("caches files and counts hits and misses", func() {
+		hunks := newHunks(&NotifierOptions{SourceFS: sourceFS})
+
+		for _, line := range []int{1, 4, 7} {
+			_, err := hunks.getCode("github.com/airbrake/gobrake/v4/pkg/app.go", line)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		stats := hunks.cache.Stats()
+		Expect(stats.Hits).To(Equal(uint64(2)))
+		Expect(stats.Misses).To(Equal(uint64(1)))
+		Expect(stats.Size).To(Equal(len(source)))
+	})
+
+	It("bounds cached failed reads", func() {
+		hunks := newHunks(&NotifierOptions{SourceFS: sourceFS})
+
+		for i := 0; i < codeCacheMaxLen+10; i++ {
+			_, err := hunks.getCode(filepath.Join("missing", strconv.Itoa(i)+".go"), 1)
+			Expect(err).To(HaveOccurred())
+		}
+
+		stats := hunks.cache.Stats()
+		Expect(stats.Len).To(Equal(codeCacheMaxLen))
+		Expect(stats.Size).To(Equal(codeCacheMaxLen * codeCacheErrSize))
+	})
+
+	It("reports cache hits and misses in notifier stats", func() {
+		configServer := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte(`{}`))
+			}))
+		defer configServer.Close()
+
+		notifier := NewNotifierWithOptions(&NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			RemoteConfigHost: configServer.URL,
+			SourceFS:         sourceFS,
+		})
+		defer notifier.Close()
+
+		notice := &Notice{Errors: []Error{{
+			Backtrace: []StackFrame{
+				{File: "github.com/airbrake/gobrake/v4/pkg/app.go", Line: 2},
+				{File: "github.com/airbrake/gobrake/v4/pkg/app.go", Line: 5},
+			},
+		}}}
+		newCodeHunksFilter(notifier.codeHunks)(notice)
+
+		stats := notifier.Stats()
+		Expect(stats.CodeHunkCacheHits).To(BeNumerically("==", 1))
+		Expect(stats.CodeHunkCacheMisses).To(BeNumerically("==", 1))
+	})
+
+	It("reloads changed files", func() {
+		fsys := fstest.MapFS{
+			"app.go": &fstest.MapFile{Data: []byte("old\n"), ModTime: time.Unix(1, 0)},
+		}
+		hunks := newHunks(&NotifierOptions{SourceFS: fsys})
+		file := "github.com/airbrake/gobrake/v4/app.go"
+
+		code, err := hunks.getCode(file, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code[1]).To(Equal("old"))
+
+		fsys["app.go"] = &fstest.MapFile{Data: []byte("new\n"), ModTime: time.Unix(2, 0)}
+		code, err = hunks.getCode(file, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code[1]).To(Equal("new"))
+
+		fsys["app.go"] = &fstest.MapFile{Data: []byte("newer\n"), ModTime: time.Unix(2, 0)}
+		code, err = hunks.getCode(file, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code[1]).To(Equal("newer"))
+	})
+})
+
+func BenchmarkCodeHunks(b *testing.B) {
+	// recurse returns a notice with a backtrace that is 32 frames deep.
+	var recurse func(depth int) *Notice
+	recurse = func(depth int) *Notice {
+		if depth == 0 {
+			return NewNotice("benchmark", nil, 0)
+		}
+		return recurse(depth - 1)
+	}
+	notice := recurse(64)
+
+	opt := &NotifierOptions{}
+	opt.init()
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			filter := newCodeHunksFilter(newCodeHunks(opt))
+			filter(notice)
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		filter := newCodeHunksFilter(newCodeHunks(opt))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			filter(notice)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package gobrake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Deploy describes a deploy of the application. Empty fields are populated
+// from the notifier options, git repository and build info.
+type Deploy struct {
+	Environment string `json:"environment"`
+	Username    string `json:"username"`
+	Repository  string `json:"repository"`
+	Revision    string `json:"revision"`
+	Version     string `json:"version"`
+}
+
+func (n *Notifier) fillDeploy(d *Deploy) {
+	if d.Environment == "" {
+		d.Environment = n.opt.Environment
+	}
+	if d.Revision == "" {
+		d.Revision = n.opt.Revision
+	}
+
+	var info *gitInfo
+	if rootDir, _ := getDefaultContext()["rootDirectory"].(string); rootDir != "" {
+		if dir, ok := findGitDir(rootDir); ok {
+			info = getGitInfo(dir)
+		}
+	}
+	if info != nil {
+		if d.Repository == "" {
+			d.Repository = info.Repository
+		}
+		if d.Revision == "" {
+			d.Revision = info.Revision
+		}
+		if d.Username == "" && info.LastCheckout != nil {
+			d.Username = info.LastCheckout.Username
+		}
+	}
+
+	if d.Revision == "" {
+		d.Revision = buildVCSRevision()
+	}
+	if d.Version == "" {
+		if bi := getBuildInfo(); bi != nil && bi.Main.Version != "(devel)" {
+			d.Version = bi.Main.Version
+		}
+	}
+}
+
+// NotifyDeploy notifies Airbrake about the deploy.
+func (n *Notifier) NotifyDeploy(c context.Context, d Deploy) error {
+	n.fillDeploy(&d)
+
+	buf := buffers.Get().(*bytes.Buffer)
+	defer buffers.Put(buf)
+
+	buf.Reset()
+	err := json.NewEncoder(buf).Encode(&d)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/api/v4/projects/%d/deploys",
+			n.opt.Host, n.opt.ProjectId),
+		buf,
+	)
+	if err != nil {
+		return err
+	}
+	if c != nil {
+		req = req.WithContext(c)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+n.opt.ProjectKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := n.opt.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	buf.Reset()
+	_, err = buf.ReadFrom(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return errUnauthorized
+	case httpStatusTooManyRequests:
+		return errIPRateLimited
+	case httpEnhanceYourCalm:
+		return errAccountRateLimited
+	case http.StatusBadRequest:
+		var sendResp sendResponse
+		err = json.NewDecoder(buf).Decode(&sendResp)
+		if err != nil {
+			return err
+		}
+		return errors.New(sendResp.Message)
+	}
+
+	return fmt.Errorf("got unexpected response status=%q", resp.Status)
+}
@@ -0,0 +1,92 @@
+package gobrake_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+var _ = Describe("NotifyDeploy", func() {
+	var notifier *gobrake.Notifier
+	var sentDeploy *gobrake.Deploy
+	var deployReq *http.Request
+	var status int
+
+	BeforeEach(func() {
+		sentDeploy = nil
+		status = http.StatusCreated
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			deployReq = req
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			sentDeploy = new(gobrake.Deploy)
+			err = json.Unmarshal(b, sentDeploy)
+			Expect(err).To(BeNil())
+
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		configServer := newConfigServer()
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: configServer.URL,
+			Environment:      "production",
+			Revision:         "d34db33f",
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("sends deploy", func() {
+		err := notifier.NotifyDeploy(context.Background(), gobrake.Deploy{
+			Environment: "staging",
+			Username:    "john",
+			Repository:  "https://github.com/airbrake/gobrake",
+			Revision:    "cafebabe",
+			Version:     "v1.2.3",
+		})
+		Expect(err).To(BeNil())
+
+		Expect(deployReq.Method).To(Equal("POST"))
+		Expect(deployReq.URL.Path).To(Equal("/api/v4/projects/1/deploys"))
+		Expect(deployReq.Header.Get("Authorization")).To(Equal("Bearer key"))
+		Expect(*sentDeploy).To(Equal(gobrake.Deploy{
+			Environment: "staging",
+			Username:    "john",
+			Repository:  "https://github.com/airbrake/gobrake",
+			Revision:    "cafebabe",
+			Version:     "v1.2.3",
+		}))
+	})
+
+	It("defaults fields from notifier options", func() {
+		err := notifier.NotifyDeploy(context.Background(), gobrake.Deploy{})
+		Expect(err).To(BeNil())
+
+		Expect(sentDeploy.Environment).To(Equal("production"))
+		Expect(sentDeploy.Revision).To(Equal("d34db33f"))
+	})
+
+	It("returns error on invalid project key", func() {
+		status = http.StatusUnauthorized
+
+		err := notifier.NotifyDeploy(context.Background(), gobrake.Deploy{})
+		Expect(err).To(MatchError("gobrake: unauthorized: invalid project id or key"))
+	})
+})
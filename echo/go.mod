@@ -0,0 +1,33 @@
+module github.com/airbrake/gobrake/v4/echo
+
+go 1.21
+
+require (
+	github.com/airbrake/gobrake/v4 v4.3.0
+	github.com/labstack/echo/v4 v4.11.4
+	github.com/onsi/ginkgo v1.8.0
+	github.com/onsi/gomega v1.5.0
+)
+
+require (
+	github.com/caio/go-tdigest v3.1.0+incompatible // indirect
+	github.com/hpcloud/tail v1.0.0 // indirect
+	github.com/jonboulle/clockwork v0.1.1-0.20190114141812-62fb9bc030d1 // indirect
+	github.com/labstack/gommon v0.4.2 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/net v0.19.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/fsnotify.v1 v1.4.7 // indirect
+	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
+
+// Builds against the working tree during development. Replace directives
+// are ignored by dependents, which use the required release.
+replace github.com/airbrake/gobrake/v4 => ../
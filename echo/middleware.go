@@ -0,0 +1,90 @@
+// Package echo provides an Echo middleware that reports errors and panics to
+// Airbrake and collects route stats.
+package echo
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/airbrake/gobrake/v4"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewMiddleware returns a middleware that reports route stats using Echo
+// route paths, e.g. /users/:id, reports panics as critical notices and
+// reports errors returned by handlers that result in 5xx responses. The
+// route metric and the request are stored in the request context.
+//
+// Errors and panics are passed to the Echo error handler, so the middleware
+// records the final status code. http.ErrAbortHandler is not reported and is
+// re-panicked after the route stats are recorded.
+func NewMiddleware(notifier *gobrake.Notifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			req := c.Request()
+			ctx, metric := gobrake.NewRouteMetric(req.Context(), req.Method, c.Path())
+			ctx = gobrake.ContextWithRequest(ctx, req)
+			c.SetRequest(req.WithContext(ctx))
+
+			defer func() {
+				v := recover()
+				if v != nil && v != http.ErrAbortHandler {
+					notifyPanic(notifier, c, v)
+					err = echo.ErrInternalServerError
+				}
+
+				if err != nil {
+					if status := errorStatus(err); status >= 500 && v == nil {
+						notice := notifier.Notice(err, c.Request(), 1)
+						notifyNotice(notifier, c, notice)
+					}
+					// Like the Echo logger middleware, invoke the error handler
+					// to write the response. It is not invoked again for
+					// committed responses.
+					c.Error(err)
+				}
+
+				metric.Route = routeName(c)
+				metric.StatusCode = c.Response().Status
+				metric.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+				_ = notifier.Routes.Notify(ctx, metric)
+
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+func notifyPanic(notifier *gobrake.Notifier, c echo.Context, v interface{}) {
+	// Skip this function, the deferred function and runtime.gopanic.
+	notice := notifier.Notice(v, c.Request(), 3)
+	notice.Context["severity"] = "critical"
+	notifyNotice(notifier, c, notice)
+}
+
+func notifyNotice(notifier *gobrake.Notifier, c echo.Context, notice *gobrake.Notice) {
+	notice.Context["route"] = routeName(c)
+	notifier.Notify(notice, c.Request())
+}
+
+// errorStatus returns the status code of the response Echo writes for err.
+func errorStatus(err error) int {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// routeName returns the route path, e.g. /users/:id.
+func routeName(c echo.Context) string {
+	if path := c.Path(); path != "" {
+		return path
+	}
+	return "UNKNOWN"
+}
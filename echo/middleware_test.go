@@ -0,0 +1,163 @@
+package echo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	echobrake "github.com/airbrake/gobrake/v4/echo"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestEcho(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "echo")
+}
+
+var _ = Describe("NewMiddleware", func() {
+	var notifier *gobrake.Notifier
+	var e *echo.Echo
+
+	var mu sync.Mutex
+	var sentNotices []*gobrake.Notice
+	var metric *gobrake.RouteMetric
+
+	BeforeEach(func() {
+		sentNotices = nil
+		metric = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotices = append(sentNotices, notice)
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			metric = m
+			return nil
+		})
+
+		e = echo.New()
+		e.Use(echobrake.NewMiddleware(notifier))
+		e.GET("/users/:id", func(c echo.Context) error {
+			Expect(gobrake.ContextRouteMetric(c.Request().Context())).NotTo(BeNil())
+			return c.JSON(http.StatusAccepted, map[string]string{"id": c.Param("id")})
+		})
+		e.GET("/panic", func(c echo.Context) error {
+			panic("boom")
+		})
+		e.GET("/fail", func(c echo.Context) error {
+			return errors.New("db is down")
+		})
+		e.GET("/forbidden", func(c echo.Context) error {
+			return echo.ErrForbidden
+		})
+		e.GET("/abort", func(c echo.Context) error {
+			panic(http.ErrAbortHandler)
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		notifier.Flush()
+		return rec
+	}
+
+	notices := func() []*gobrake.Notice {
+		mu.Lock()
+		defer mu.Unlock()
+		return sentNotices
+	}
+
+	It("reports route path, status and content type", func() {
+		rec := serve("/users/123")
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+
+		Expect(metric).NotTo(BeNil())
+		Expect(metric.Method).To(Equal("GET"))
+		Expect(metric.Route).To(Equal("/users/:id"))
+		Expect(metric.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(metric.ContentType).To(Equal("application/json; charset=UTF-8"))
+		Expect(notices()).To(BeEmpty())
+	})
+
+	It("reports panics", func() {
+		rec := serve("/panic")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("boom"))
+		Expect(notice.Errors[0].Backtrace[0].File).To(HaveSuffix("middleware_test.go"))
+		Expect(notice.Context["severity"]).To(Equal("critical"))
+		Expect(notice.Context["route"]).To(Equal("/panic"))
+	})
+
+	It("reports route stats of aborted requests and re-panics", func() {
+		Expect(func() {
+			e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+		}).To(Panic())
+		notifier.Flush()
+
+		Expect(metric).NotTo(BeNil())
+		Expect(metric.Route).To(Equal("/abort"))
+		Expect(notices()).To(BeEmpty())
+	})
+
+	It("reports errors with 5xx status codes", func() {
+		rec := serve("/fail")
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("db is down"))
+		Expect(notice.Context["route"]).To(Equal("/fail"))
+		Expect(notice.Context["url"]).To(Equal("/fail"))
+	})
+
+	It("doesn't report errors with 4xx status codes", func() {
+		rec := serve("/forbidden")
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		Expect(metric.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(notices()).To(BeEmpty())
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/airbrake/gobrake/v4"
+	muxbrake "github.com/airbrake/gobrake/v4/mux"
 	ng "github.com/airbrake/gobrake/v4/negroni"
 	"github.com/gorilla/mux"
 	"github.com/urfave/negroni"
@@ -22,9 +23,11 @@ func main() {
 		ProjectKey:  *projectKey,
 		Environment: *env,
 	})
-	n.Use(ng.NewMiddleware(notifier))
 	r := mux.NewRouter()
 	r.HandleFunc("/ping", ping)
+	n.Use(ng.NewMiddlewareWithOptions(notifier, &ng.Options{
+		RouteName: muxbrake.RouterRouteName(r),
+	}))
 	n.UseHandler(r)
 	log.Fatal(http.ListenAndServe(":8080", n))
 }
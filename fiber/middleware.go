@@ -0,0 +1,121 @@
+// Package fiber provides a Fiber middleware that reports errors and panics
+// to Airbrake and collects route stats.
+package fiber
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/airbrake/gobrake/v4"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/valyala/fasthttp"
+)
+
+// NewMiddleware returns a middleware that reports route stats using Fiber
+// route paths, e.g. /users/:id, reports panics as critical notices and
+// reports errors returned by handlers that result in 5xx responses. The
+// route metric is stored in c.UserContext().
+//
+// Panics are returned as fiber.ErrInternalServerError, so they are answered
+// by the application error handler.
+func NewMiddleware(notifier *gobrake.Notifier) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		// Method and path point into buffers fasthttp reuses for the next
+		// request, while the metric outlives the request. The route is known
+		// only after c.Next, so queries see the normalized path until then.
+		ctx, metric := gobrake.NewRouteMetric(c.UserContext(),
+			utils.CopyString(c.Method()),
+			gobrake.NormalizePath(utils.CopyString(c.Path())))
+		c.SetUserContext(ctx)
+		// The route of the middleware itself. It is still the current route
+		// after c.Next when no other route matched the request.
+		useRoute := c.Route()
+
+		defer func() {
+			v := recover()
+			if v != nil {
+				notifyPanic(notifier, c, useRoute, v)
+				err = fiber.ErrInternalServerError
+			}
+
+			metric.Route = routeName(c, useRoute)
+			metric.StatusCode = c.Response().StatusCode()
+			metric.ContentType = string(c.Response().Header.ContentType())
+			if err != nil {
+				// The error handler writes the response after the middleware
+				// returns.
+				metric.StatusCode = errorStatus(err)
+				metric.ContentType = ""
+				if v == nil && metric.StatusCode >= 500 {
+					notice := notifier.Notice(err, nil, 1)
+					notifyNotice(notifier, c, useRoute, notice)
+				}
+			}
+			_ = notifier.Routes.Notify(ctx, metric)
+		}()
+
+		return c.Next()
+	}
+}
+
+func notifyPanic(notifier *gobrake.Notifier, c *fiber.Ctx, useRoute *fiber.Route, v interface{}) {
+	// Skip this function, the deferred function and runtime.gopanic.
+	notice := notifier.Notice(v, nil, 3)
+	notice.Context["severity"] = "critical"
+	notifyNotice(notifier, c, useRoute, notice)
+}
+
+func notifyNotice(notifier *gobrake.Notifier, c *fiber.Ctx, useRoute *fiber.Route, notice *gobrake.Notice) {
+	SetRequestCtx(notifier, notice, c.Context())
+	notice.Context["route"] = routeName(c, useRoute)
+	notifier.Notify(notice, nil)
+}
+
+// SetRequestCtx fills the notice context and environment from a fasthttp
+// request like Notice.SetRequest does for *http.Request. The client address
+// is resolved with the TrustedProxies and ClientIPResolver options of the
+// notifier.
+func SetRequestCtx(notifier *gobrake.Notifier, notice *gobrake.Notice, ctx *fasthttp.RequestCtx) {
+	notice.Context["url"] = ctx.URI().String()
+	notice.Context["httpMethod"] = string(ctx.Method())
+	if ua := ctx.UserAgent(); len(ua) > 0 {
+		notice.Context["userAgent"] = string(ua)
+	}
+
+	header := make(http.Header)
+	ctx.Request.Header.VisitAll(func(k, v []byte) {
+		header.Add(string(k), string(v))
+	})
+	notice.Context["userAddr"] = notifier.ClientIP(&http.Request{
+		Header:     header,
+		RemoteAddr: ctx.RemoteAddr().String(),
+	})
+	for k, v := range header {
+		if len(v) == 1 {
+			notice.Env[k] = v[0]
+		} else {
+			notice.Env[k] = v
+		}
+	}
+}
+
+// errorStatus returns the status code of the response Fiber writes for err.
+func errorStatus(err error) int {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return fe.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// routeName returns the route path, e.g. /users/:id. Requests that only
+// matched the middleware route are reported as UNKNOWN.
+func routeName(c *fiber.Ctx, useRoute *fiber.Route) string {
+	route := c.Route()
+	if route == nil || route == useRoute || route.Path == "" {
+		return "UNKNOWN"
+	}
+	return route.Path
+}
@@ -0,0 +1,237 @@
+package fiber_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	fiberbrake "github.com/airbrake/gobrake/v4/fiber"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+func TestFiber(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "fiber")
+}
+
+var _ = Describe("NewMiddleware", func() {
+	var notifier *gobrake.Notifier
+	var app *fiber.App
+
+	var mu sync.Mutex
+	var sentNotices []*gobrake.Notice
+	var metric *gobrake.RouteMetric
+	var metrics []*gobrake.RouteMetric
+
+	BeforeEach(func() {
+		sentNotices = nil
+		metric = nil
+		metrics = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotices = append(sentNotices, notice)
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			metric = m
+			metrics = append(metrics, m)
+			return nil
+		})
+
+		app = fiber.New(fiber.Config{DisableStartupMessage: true})
+		app.Use(fiberbrake.NewMiddleware(notifier))
+		app.Get("/users/:id", func(c *fiber.Ctx) error {
+			Expect(gobrake.ContextRouteMetric(c.UserContext())).NotTo(BeNil())
+			return c.Status(http.StatusAccepted).JSON(fiber.Map{"id": c.Params("id")})
+		})
+		app.Post("/users/:id", func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusCreated)
+		})
+		app.Get("/panic", func(c *fiber.Ctx) error {
+			panic("boom")
+		})
+		app.Get("/fail", func(c *fiber.Ctx) error {
+			return errors.New("db is down")
+		})
+		app.Get("/forbidden", func(c *fiber.Ctx) error {
+			return fiber.ErrForbidden
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	serve := func(path string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("User-Agent", "test")
+		req.Header.Add("X-Multi", "a")
+		req.Header.Add("X-Multi", "b")
+		resp, err := app.Test(req)
+		Expect(err).NotTo(HaveOccurred())
+		notifier.Flush()
+		return resp
+	}
+
+	notices := func() []*gobrake.Notice {
+		mu.Lock()
+		defer mu.Unlock()
+		return sentNotices
+	}
+
+	It("reports route path, status and content type", func() {
+		resp := serve("/users/123")
+		Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+
+		Expect(metric).NotTo(BeNil())
+		Expect(metric.Method).To(Equal("GET"))
+		Expect(metric.Route).To(Equal("/users/:id"))
+		Expect(metric.StatusCode).To(Equal(http.StatusAccepted))
+		Expect(metric.ContentType).To(Equal("application/json"))
+		Expect(notices()).To(BeEmpty())
+	})
+
+	It("keeps method and route of earlier requests intact", func() {
+		methods := []string{"GET", "POST", "DELETE"}
+		for i := 0; i < 100; i++ {
+			req := httptest.NewRequest(methods[i%len(methods)], "/users/"+strconv.Itoa(i), nil)
+			_, err := app.Test(req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(metrics).To(HaveLen(100))
+		for i, m := range metrics {
+			Expect(m.Method).To(Equal(methods[i%len(methods)]))
+			if m.Method == "DELETE" {
+				Expect(m.Route).To(Equal("UNKNOWN"))
+			} else {
+				Expect(m.Route).To(Equal("/users/:id"))
+			}
+		}
+	})
+
+	It("reports panics with the request", func() {
+		resp := serve("/panic")
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("boom"))
+		Expect(notice.Errors[0].Backtrace[0].File).To(HaveSuffix("middleware_test.go"))
+		Expect(notice.Context["severity"]).To(Equal("critical"))
+		Expect(notice.Context["route"]).To(Equal("/panic"))
+		Expect(notice.Context["url"]).To(Equal("http://example.com/panic"))
+		Expect(notice.Context["httpMethod"]).To(Equal("GET"))
+		Expect(notice.Context["userAgent"]).To(Equal("test"))
+		Expect(notice.Context["userAddr"]).To(Equal("0.0.0.0"))
+		Expect(notice.Env["X-Multi"]).To(Equal([]interface{}{"a", "b"}))
+	})
+
+	It("reports errors with 5xx status codes", func() {
+		resp := serve("/fail")
+		Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("db is down"))
+		Expect(notice.Context["route"]).To(Equal("/fail"))
+	})
+
+	It("doesn't report errors with 4xx status codes", func() {
+		resp := serve("/forbidden")
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(metric.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(notices()).To(BeEmpty())
+	})
+
+	It("reports unmatched requests as UNKNOWN", func() {
+		resp := serve("/missing")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(metric.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(metric.Route).To(Equal("UNKNOWN"))
+	})
+})
+
+var _ = Describe("SetRequestCtx", func() {
+	var server *httptest.Server
+	var ctx *fasthttp.RequestCtx
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+		req := new(fasthttp.Request)
+		req.SetRequestURI("http://example.com/users")
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		ctx = new(fasthttp.RequestCtx)
+		ctx.Init(req, &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}, nil)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	userAddr := func(trustedProxies []string) interface{} {
+		notifier := gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+			TrustedProxies:   trustedProxies,
+		})
+		defer notifier.Close()
+
+		notice := notifier.Notice(errors.New("boom"), nil, 0)
+		fiberbrake.SetRequestCtx(notifier, notice, ctx)
+		return notice.Context["userAddr"]
+	}
+
+	It("resolves the client address behind trusted proxies", func() {
+		Expect(userAddr([]string{"10.0.0.0/8"})).To(Equal("203.0.113.7"))
+	})
+
+	It("ignores forwarding headers of untrusted peers", func() {
+		Expect(userAddr([]string{})).To(Equal("10.0.0.1"))
+	})
+})
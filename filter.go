@@ -1,9 +1,9 @@
 package gobrake
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
 	"regexp"
 	"strings"
 )
@@ -17,6 +17,11 @@ func newNotifierFilter(notifier *Notifier) func(*Notice) *Notice {
 		if opt.Revision != "" {
 			notice.Context["revision"] = opt.Revision
 		}
+		if opt.ReportDependencies {
+			if deps := getBuildDependencies(); deps != nil {
+				notice.Context["dependencies"] = deps
+			}
+		}
 		return notice
 	}
 }
@@ -56,31 +61,6 @@ func filterByKey(values map[string]interface{}, key interface{}) map[string]inte
 	return values
 }
 
-func gopathFilter(notice *Notice) *Notice {
-	s, ok := notice.Context["gopath"].(string)
-	if !ok {
-		return notice
-	}
-
-	dirs := filepath.SplitList(s)
-	for i := range notice.Errors {
-		backtrace := notice.Errors[i].Backtrace
-		for j := range backtrace {
-			frame := &backtrace[j]
-
-			for _, dir := range dirs {
-				dir = filepath.Join(dir, "src")
-				if strings.HasPrefix(frame.File, dir) {
-					frame.File = strings.Replace(frame.File, dir, "/GOPATH", 1)
-					break
-				}
-			}
-		}
-	}
-
-	return notice
-}
-
 func gitFilter(notice *Notice) *Notice {
 	rootDir, _ := notice.Context["rootDirectory"].(string)
 	if rootDir == "" {
@@ -110,6 +90,10 @@ func gitFilter(notice *Notice) *Notice {
 		notice.Context["lastCheckout"] = info.LastCheckout
 	}
 
+	if modified := info.Modified(); notice.Context["revisionModified"] == nil && modified != nil {
+		notice.Context["revisionModified"] = *modified
+	}
+
 	return notice
 }
 
@@ -126,21 +110,23 @@ func httpUnsolicitedResponseFilter(notice *Notice) *Notice {
 	return nil
 }
 
-func codeHunksFilter(notice *Notice) *Notice {
-	for i := range notice.Errors {
-		error := &notice.Errors[i]
-		for j := range error.Backtrace {
-			frame := &error.Backtrace[j]
-			code, err := getCode(frame.File, frame.Line)
-			if err != nil {
-				if !os.IsNotExist(err) {
-					logger.Printf("getCode file=%q line=%d failed: %s",
-						frame.File, frame.Line, err)
+func newCodeHunksFilter(hunks *codeHunks) func(*Notice) *Notice {
+	return func(notice *Notice) *Notice {
+		for i := range notice.Errors {
+			error := &notice.Errors[i]
+			for j := range error.Backtrace {
+				frame := &error.Backtrace[j]
+				code, err := hunks.getCode(frame.File, frame.Line)
+				if err != nil {
+					if !errors.Is(err, fs.ErrNotExist) {
+						logger.Printf("getCode file=%q line=%d failed: %s",
+							frame.File, frame.Line, err)
+					}
+					continue
 				}
-				continue
+				frame.Code = code
 			}
-			frame.Code = code
 		}
+		return notice
 	}
-	return notice
 }
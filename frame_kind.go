@@ -0,0 +1,130 @@
+package gobrake
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Kinds of backtrace frames.
+const (
+	FrameApp     = "app"     // main module
+	FrameLibrary = "library" // dependencies
+	FrameStdlib  = "stdlib"  // standard library
+)
+
+var (
+	mainModuleOnce sync.Once
+	mainModule     string
+)
+
+// mainModulePath returns the main module path from build info falling back
+// to go.mod of the working directory.
+func mainModulePath() string {
+	mainModuleOnce.Do(func() {
+		if bi := getBuildInfo(); bi != nil && bi.Main.Path != "" {
+			mainModule = bi.Main.Path
+			return
+		}
+		wd, _ := getDefaultContext()["rootDirectory"].(string)
+		if wd == "" {
+			return
+		}
+		if dir, ok := findModuleRoot(wd); ok {
+			mainModule = goModModulePath(filepath.Join(dir, "go.mod"))
+		}
+	})
+	return mainModule
+}
+
+func goModModulePath(file string) string {
+	fd, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer fd.Close()
+
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(line[len("module "):]), `"`)
+		}
+	}
+	return ""
+}
+
+var (
+	gorootOnce sync.Once
+	goroot     string
+)
+
+// buildGOROOT returns GOROOT of the machine that built the binary. It is
+// derived from the file of a runtime function.
+func buildGOROOT() string {
+	gorootOnce.Do(func() {
+		pc := reflect.ValueOf(runtime.Gosched).Pointer()
+		fn := runtime.FuncForPC(pc)
+		if fn == nil {
+			return
+		}
+		file, _ := fn.FileLine(pc)
+		file = filepath.ToSlash(file)
+		if ind := strings.LastIndex(file, "/src/runtime/"); ind > 0 {
+			goroot = file[:ind]
+		}
+	})
+	return goroot
+}
+
+// frameKind classifies the frame by its package and file.
+func frameKind(pkg, file string) string {
+	if isAppPackage(pkg) {
+		return FrameApp
+	}
+	if root := buildGOROOT(); root != "" {
+		if _, ok := trimPathPrefix(file, root); ok {
+			return FrameStdlib
+		}
+	}
+	// Import paths of the standard library have no dot in the first element.
+	first := pkg
+	if ind := strings.IndexByte(first, '/'); ind != -1 {
+		first = first[:ind]
+	}
+	if first != "" && !strings.Contains(first, ".") {
+		return FrameStdlib
+	}
+	return FrameLibrary
+}
+
+func isAppPackage(pkg string) bool {
+	pkg = strings.TrimSuffix(pkg, "_test")
+	if pkg == "main" {
+		return true
+	}
+	mod := mainModulePath()
+	if mod == "" {
+		return false
+	}
+	return pkg == mod || strings.HasPrefix(pkg, mod+"/")
+}
+
+// collapseLibraryFrames keeps only the first and the last frame of every run
+// of library frames.
+func collapseLibraryFrames(frames []StackFrame) []StackFrame {
+	collapsed := frames[:0]
+	for i := range frames {
+		inRun := frames[i].Kind == FrameLibrary &&
+			i > 0 && frames[i-1].Kind == FrameLibrary &&
+			i+1 < len(frames) && frames[i+1].Kind == FrameLibrary
+		if !inRun {
+			collapsed = append(collapsed, frames[i])
+		}
+	}
+	return collapsed
+}
@@ -0,0 +1,88 @@
+package gobrake
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("frameKind", func() {
+	It("classifies frames by package", func() {
+		tests := []struct {
+			pkg  string
+			kind string
+		}{
+			{"main", FrameApp},
+			{"github.com/airbrake/gobrake/v4", FrameApp},
+			{"github.com/airbrake/gobrake/v4/internal/testpkg1", FrameApp},
+			{"github.com/airbrake/gobrake/v4_test", FrameApp},
+			{"github.com/airbrake/gobrake/v4foo", FrameLibrary},
+			{"github.com/onsi/ginkgo", FrameLibrary},
+			{"net/http", FrameStdlib},
+			{"runtime", FrameStdlib},
+		}
+
+		for _, test := range tests {
+			Expect(frameKind(test.pkg, "")).To(Equal(test.kind), test.pkg)
+		}
+	})
+
+	It("classifies GOROOT files as stdlib", func() {
+		file := buildGOROOT() + "/src/example.com/pkg/file.go"
+		Expect(frameKind("example.com/pkg", file)).To(Equal(FrameStdlib))
+	})
+})
+
+var _ = Describe("collapseLibraryFrames", func() {
+	It("keeps the first and the last frame of library runs", func() {
+		frames := []StackFrame{
+			{Func: "a", Kind: FrameApp},
+			{Func: "l1", Kind: FrameLibrary},
+			{Func: "l2", Kind: FrameLibrary},
+			{Func: "l3", Kind: FrameLibrary},
+			{Func: "l4", Kind: FrameLibrary},
+			{Func: "s", Kind: FrameStdlib},
+			{Func: "l5", Kind: FrameLibrary},
+			{Func: "l6", Kind: FrameLibrary},
+		}
+
+		var funcs []string
+		for _, f := range collapseLibraryFrames(frames) {
+			funcs = append(funcs, f.Func)
+		}
+		Expect(funcs).To(Equal([]string{"a", "l1", "l4", "s", "l5", "l6"}))
+	})
+})
+
+var _ = Describe("getBacktrace", func() {
+	It("sets frame kinds and component", func() {
+		notice := newNotice("hello", nil, 0, defaultNoticeOptions)
+
+		Expect(notice.Context["component"]).To(Equal("github.com/airbrake/gobrake/v4"))
+		frames := notice.Errors[0].Backtrace
+		Expect(frames[0].Kind).To(Equal(FrameApp))
+		Expect(frames[len(frames)-1].Kind).To(Equal(FrameStdlib))
+
+		var library int
+		for _, f := range frames {
+			if f.Kind == FrameLibrary {
+				library++
+			}
+		}
+		Expect(library).To(BeNumerically(">", 2))
+	})
+
+	It("collapses library frames", func() {
+		opt := &NotifierOptions{CollapseLibraryFrames: true}
+		opt.init()
+		notice := newNotice("hello", nil, 0, opt)
+
+		frames := notice.Errors[0].Backtrace
+		Expect(len(frames)).To(BeNumerically("<=", defaultBacktraceDepth))
+		for i := 2; i < len(frames); i++ {
+			run := frames[i].Kind == FrameLibrary &&
+				frames[i-1].Kind == FrameLibrary &&
+				frames[i-2].Kind == FrameLibrary
+			Expect(run).To(BeFalse())
+		}
+	})
+})
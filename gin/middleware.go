@@ -1,45 +1,73 @@
 package gin
 
 import (
-	"context"
-	"sync"
+	"net/http"
 
 	"github.com/airbrake/gobrake/v4"
 
 	"github.com/gin-gonic/gin"
 )
 
+// NewMiddleware returns a middleware that reports route stats, panics and
+// errors collected in c.Errors. The route metric and the request are stored
+// in the request context, so spans started by handlers are attached to the
+// metric. Panics are answered with 500 Internal Server Error unless the
+// response was already started. http.ErrAbortHandler is not reported and is
+// re-panicked.
+//
+// The engine argument is not used and is kept for compatibility.
 func NewMiddleware(engine *gin.Engine, notifier *gobrake.Notifier) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		routeName := routeName(c, engine)
-		_, metric := gobrake.NewRouteMetric(context.TODO(), c.Request.Method, routeName)
+		ctx, metric := gobrake.NewRouteMetric(
+			c.Request.Context(), c.Request.Method, routeName(c))
+		ctx = gobrake.ContextWithRequest(ctx, c.Request)
+		c.Request = c.Request.WithContext(ctx)
 
-		c.Next()
+		defer func() {
+			v := recover()
+			if v != nil && v != http.ErrAbortHandler {
+				notifyPanic(notifier, c, v)
+				if c.Writer.Written() {
+					c.Abort()
+				} else {
+					c.AbortWithStatus(http.StatusInternalServerError)
+				}
+			}
 
-		metric.StatusCode = c.Writer.Status()
-		_ = notifier.Routes.Notify(context.TODO(), metric)
-	}
-}
+			for _, err := range c.Errors {
+				notice := notifier.Notice(err.Err, c.Request, 1)
+				notice.Context["route"] = metric.Route
+				if err.Meta != nil {
+					notice.Params["meta"] = err.Meta
+				}
+				notifier.Notify(notice, c.Request)
+			}
 
-func routeName(c *gin.Context, engine *gin.Engine) string {
-	initPathMap(engine)
-	route, ok := pathMap[c.HandlerName()]
-	if ok {
-		return route
+			metric.StatusCode = c.Writer.Status()
+			metric.ContentType = c.Writer.Header().Get("Content-Type")
+			_ = notifier.Routes.Notify(ctx, metric)
+
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+		}()
+
+		c.Next()
 	}
-	return "UNKNOWN"
 }
 
-var (
-	pathMapOnce sync.Once
-	pathMap     map[string]string
-)
+func notifyPanic(notifier *gobrake.Notifier, c *gin.Context, v interface{}) {
+	// Skip this function, the deferred function and runtime.gopanic.
+	notice := notifier.Notice(v, c.Request, 3)
+	notice.Context["severity"] = "critical"
+	notice.Context["route"] = routeName(c)
+	notifier.Notify(notice, c.Request)
+}
 
-func initPathMap(engine *gin.Engine) {
-	pathMapOnce.Do(func() {
-		pathMap = make(map[string]string)
-		for _, ri := range engine.Routes() {
-			pathMap[ri.Handler] = ri.Path
-		}
-	})
+// routeName returns the route pattern, e.g. /users/:id.
+func routeName(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+	return "UNKNOWN"
 }
@@ -0,0 +1,191 @@
+package gin_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	ginbrake "github.com/airbrake/gobrake/v4/gin"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "gin")
+}
+
+var _ = Describe("NewMiddleware", func() {
+	var notifier *gobrake.Notifier
+	var engine *gin.Engine
+
+	var mu sync.Mutex
+	var sentNotices []*gobrake.Notice
+	var metric *gobrake.RouteMetric
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		sentNotices = nil
+		metric = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotices = append(sentNotices, notice)
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			metric = m
+			return nil
+		})
+
+		engine = gin.New()
+		engine.Use(ginbrake.NewMiddleware(engine, notifier))
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		notifier.Flush()
+		return w
+	}
+
+	It("reports route stats with route pattern and content type", func() {
+		var reqMetric *gobrake.RouteMetric
+		handler := func(c *gin.Context) {
+			reqMetric = gobrake.ContextRouteMetric(c.Request.Context())
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		}
+		engine.GET("/users/:id", handler)
+		engine.GET("/posts/:id", handler)
+
+		get("/posts/1")
+		Expect(metric).NotTo(BeNil())
+		Expect(reqMetric).To(BeIdenticalTo(metric))
+		Expect(metric.Route).To(Equal("/posts/:id"))
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+		Expect(metric.ContentType).To(Equal("application/json; charset=utf-8"))
+
+		get("/users/1")
+		Expect(metric.Route).To(Equal("/users/:id"))
+	})
+
+	It("reports unknown routes", func() {
+		get("/missing")
+		Expect(metric.Route).To(Equal("UNKNOWN"))
+		Expect(metric.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("recovers panics", func() {
+		engine.GET("/panic", func(c *gin.Context) {
+			panic("boom")
+		})
+
+		w := get("/panic")
+		Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotices).To(HaveLen(1))
+		notice := sentNotices[0]
+		Expect(notice.Errors[0].Message).To(Equal("boom"))
+		Expect(notice.Errors[0].Backtrace[0].File).To(HaveSuffix("middleware_test.go"))
+		Expect(notice.Context["severity"]).To(Equal("critical"))
+		Expect(notice.Context["route"]).To(Equal("/panic"))
+		Expect(notice.Context["url"]).To(Equal("/panic"))
+	})
+
+	It("keeps the status of responses started before a panic", func() {
+		engine.GET("/panic", func(c *gin.Context) {
+			c.String(http.StatusAccepted, "accepted")
+			panic("boom")
+		})
+
+		w := get("/panic")
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		Expect(w.Body.String()).To(Equal("accepted"))
+		Expect(metric.StatusCode).To(Equal(http.StatusAccepted))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotices).To(HaveLen(1))
+	})
+
+	It("re-panics http.ErrAbortHandler without reporting it", func() {
+		engine.GET("/abort", func(c *gin.Context) {
+			c.Status(http.StatusAccepted)
+			c.Writer.WriteHeaderNow()
+			panic(http.ErrAbortHandler)
+		})
+
+		w := httptest.NewRecorder()
+		Expect(func() {
+			engine.ServeHTTP(w, httptest.NewRequest("GET", "/abort", nil))
+		}).To(Panic())
+		notifier.Flush()
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		Expect(metric.StatusCode).To(Equal(http.StatusAccepted))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotices).To(BeEmpty())
+	})
+
+	It("reports c.Errors", func() {
+		engine.GET("/error", func(c *gin.Context) {
+			_ = c.Error(errors.New("first")).SetMeta("id=1")
+			_ = c.Error(errors.New("second"))
+			c.Status(http.StatusBadRequest)
+		})
+
+		get("/error")
+		Expect(metric.StatusCode).To(Equal(http.StatusBadRequest))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotices).To(HaveLen(2))
+		var messages []string
+		for _, notice := range sentNotices {
+			messages = append(messages, notice.Errors[0].Message)
+			Expect(notice.Context["route"]).To(Equal("/error"))
+			Expect(notice.Context["httpMethod"]).To(Equal("GET"))
+		}
+		Expect(messages).To(ConsistOf("first", "second"))
+	})
+})
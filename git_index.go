@@ -0,0 +1,106 @@
+package gobrake
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Larger indexes are not checked because stating every file gets expensive.
+const maxIndexEntries = 20000
+
+const (
+	indexFlagAssumeValid  = 0x8000
+	indexFlagExtended     = 0x4000
+	indexFlagSkipWorktree = 0x4000 // in extended flags
+)
+
+// gitModified reports whether files tracked in the index differ from the
+// work tree. Like git, it compares file sizes and modification times
+// recorded in the index, but never hashes file contents, so touched files
+// are reported as modified. Untracked files are not detected.
+func gitModified(workTree, gitDir string) (bool, error) {
+	b, err := ioutil.ReadFile(filepath.Join(gitDir, "index"))
+	if err != nil {
+		return false, err
+	}
+
+	if len(b) < 12 || string(b[:4]) != "DIRC" {
+		return false, fmt.Errorf("invalid git index")
+	}
+	version := binary.BigEndian.Uint32(b[4:8])
+	if version != 2 && version != 3 {
+		return false, fmt.Errorf("unsupported git index version=%d", version)
+	}
+	n := binary.BigEndian.Uint32(b[8:12])
+	if n > maxIndexEntries {
+		return false, fmt.Errorf("git index has too many entries=%d", n)
+	}
+
+	b = b[12:]
+	for i := uint32(0); i < n; i++ {
+		const fixedLen = 62
+		if len(b) < fixedLen {
+			return false, fmt.Errorf("git index is truncated")
+		}
+
+		mtimeSec := binary.BigEndian.Uint32(b[8:12])
+		mtimeNsec := binary.BigEndian.Uint32(b[12:16])
+		mode := binary.BigEndian.Uint32(b[24:28])
+		size := binary.BigEndian.Uint32(b[36:40])
+		flags := binary.BigEndian.Uint16(b[60:62])
+
+		hdrLen := fixedLen
+		skip := flags&indexFlagAssumeValid != 0
+		if flags&indexFlagExtended != 0 {
+			if len(b) < fixedLen+2 {
+				return false, fmt.Errorf("git index is truncated")
+			}
+			extFlags := binary.BigEndian.Uint16(b[62:64])
+			skip = skip || extFlags&indexFlagSkipWorktree != 0
+			hdrLen += 2
+		}
+
+		nameLen := bytes.IndexByte(b[hdrLen:], 0)
+		if nameLen == -1 {
+			return false, fmt.Errorf("git index is truncated")
+		}
+		name := string(b[hdrLen : hdrLen+nameLen])
+
+		// Entries are padded with 1-8 NUL bytes to a multiple of 8 bytes.
+		entryLen := (hdrLen + nameLen + 8) &^ 7
+		if len(b) < entryLen {
+			return false, fmt.Errorf("git index is truncated")
+		}
+		b = b[entryLen:]
+
+		const gitlink = 0160000
+		if skip || mode&0170000 == gitlink {
+			continue
+		}
+
+		fi, err := os.Lstat(filepath.Join(workTree, filepath.FromSlash(name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		if uint32(fi.Size()) != size {
+			return true, nil
+		}
+		mtime := fi.ModTime()
+		if uint32(mtime.Unix()) != mtimeSec {
+			return true, nil
+		}
+		if mtimeNsec != 0 && uint32(mtime.Nanosecond()) != mtimeNsec {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -1,17 +1,25 @@
 module github.com/airbrake/gobrake/v4
 
-go 1.11
+go 1.18
 
 require (
 	github.com/caio/go-tdigest v3.1.0+incompatible
-	github.com/golang/protobuf v1.3.1 // indirect
 	github.com/jonboulle/clockwork v0.1.1-0.20190114141812-62fb9bc030d1
-	github.com/leesper/go_rng v0.0.0-20190531154944-a612b043e353 // indirect
 	github.com/onsi/ginkgo v1.8.0
 	github.com/onsi/gomega v1.5.0
 	github.com/pkg/errors v0.9.1
+	github.com/urfave/negroni v1.0.0
+)
+
+require (
+	github.com/golang/protobuf v1.3.1 // indirect
+	github.com/hpcloud/tail v1.0.0 // indirect
+	github.com/leesper/go_rng v0.0.0-20190531154944-a612b043e353 // indirect
 	golang.org/x/net v0.0.0-20190503192946-f4e77d36d62c // indirect
 	golang.org/x/sys v0.0.0-20190422165155-953cdadca894 // indirect
+	golang.org/x/text v0.3.0 // indirect
 	gonum.org/v1/gonum v0.0.0-20190628223043-536a303fd62f // indirect
+	gopkg.in/fsnotify.v1 v1.4.7 // indirect
+	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+)
+
+type fakeSpan struct {
+	finished int
+}
+
+func (s *fakeSpan) Finish() {
+	s.finished++
+}
+
+// fakeClientStream returns the responses and then io.EOF.
+type fakeClientStream struct {
+	grpc.ClientStream
+	responses int
+}
+
+func (s *fakeClientStream) RecvMsg(m interface{}) error {
+	if s.responses == 0 {
+		return io.EOF
+	}
+	s.responses--
+	return nil
+}
+
+var _ = Describe("clientStream", func() {
+	newStream := func(span *fakeSpan, serverStreams bool) *clientStream {
+		return &clientStream{
+			ClientStream:  &fakeClientStream{responses: 2},
+			ctx:           context.Background(),
+			span:          span,
+			serverStreams: serverStreams,
+		}
+	}
+
+	It("finishes span with the response of calls without server streaming", func() {
+		span := new(fakeSpan)
+		s := newStream(span, false)
+
+		Expect(s.RecvMsg(nil)).To(Succeed())
+		Expect(span.finished).To(Equal(1))
+
+		Expect(s.RecvMsg(nil)).To(Succeed())
+		Expect(s.RecvMsg(nil)).To(Equal(io.EOF))
+		Expect(span.finished).To(Equal(1))
+	})
+
+	It("finishes span at the end of server streams", func() {
+		span := new(fakeSpan)
+		s := newStream(span, true)
+
+		Expect(s.RecvMsg(nil)).To(Succeed())
+		Expect(s.RecvMsg(nil)).To(Succeed())
+		Expect(span.finished).To(Equal(0))
+
+		Expect(s.RecvMsg(nil)).To(Equal(io.EOF))
+		Expect(span.finished).To(Equal(1))
+	})
+})
@@ -0,0 +1,323 @@
+// Package grpc provides gRPC interceptors that report errors and panics to
+// Airbrake and collect per-method stats.
+package grpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/airbrake/gobrake/v4"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// routeMethod is reported as the method of gRPC route stats.
+const routeMethod = "GRPC"
+
+type Options struct {
+	// Status codes of errors that are reported as notices. Default is
+	// Unknown, DeadlineExceeded, Unimplemented, Internal, Unavailable and
+	// DataLoss.
+	ReportCodes []codes.Code
+}
+
+var defaultReportCodes = []codes.Code{
+	codes.Unknown,
+	codes.DeadlineExceeded,
+	codes.Unimplemented,
+	codes.Internal,
+	codes.Unavailable,
+	codes.DataLoss,
+}
+
+type interceptor struct {
+	notifier *gobrake.Notifier
+	report   map[codes.Code]bool
+}
+
+func newInterceptor(notifier *gobrake.Notifier, opt *Options) *interceptor {
+	if opt == nil {
+		opt = new(Options)
+	}
+	reportCodes := opt.ReportCodes
+	if reportCodes == nil {
+		reportCodes = defaultReportCodes
+	}
+
+	report := make(map[codes.Code]bool, len(reportCodes))
+	for _, code := range reportCodes {
+		report[code] = true
+	}
+	return &interceptor{
+		notifier: notifier,
+		report:   report,
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor that reports stats of every
+// call using the full method name as the route and the gRPC status code
+// mapped to an HTTP status code. Panics are reported as critical notices and
+// answered with codes.Internal. The route metric is stored in the context
+// passed to the handler.
+func UnaryServerInterceptor(notifier *gobrake.Notifier, opt *Options) grpc.UnaryServerInterceptor {
+	in := newInterceptor(notifier, opt)
+	return func(
+		ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		ctx, metric := gobrake.NewRouteMetric(ctx, routeMethod, info.FullMethod)
+
+		defer func() {
+			v := recover()
+			if v != nil {
+				in.notifyPanic(ctx, info.FullMethod, v)
+				err = status.Errorf(codes.Internal, "%v", v)
+			}
+			in.notifyServer(ctx, metric, err, v == nil)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is like UnaryServerInterceptor for streaming calls.
+// The stream context carries the route metric.
+func StreamServerInterceptor(notifier *gobrake.Notifier, opt *Options) grpc.StreamServerInterceptor {
+	in := newInterceptor(notifier, opt)
+	return func(
+		srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) (err error) {
+		ctx, metric := gobrake.NewRouteMetric(ss.Context(), routeMethod, info.FullMethod)
+
+		defer func() {
+			v := recover()
+			if v != nil {
+				in.notifyPanic(ctx, info.FullMethod, v)
+				err = status.Errorf(codes.Internal, "%v", v)
+			}
+			in.notifyServer(ctx, metric, err, v == nil)
+		}()
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor returns an interceptor that measures calls as
+// grpc.client spans of the metric found in the context and reports errors
+// with ReportCodes as notices with the server address and the outgoing
+// metadata.
+func UnaryClientInterceptor(notifier *gobrake.Notifier, opt *Options) grpc.UnaryClientInterceptor {
+	in := newInterceptor(notifier, opt)
+	return func(
+		ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		ctx, span := gobrake.ContextMetric(ctx).Start(ctx, "grpc.client")
+		p := new(peer.Peer)
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(p))...)
+		span.Finish()
+
+		md, _ := metadata.FromOutgoingContext(ctx)
+		in.notifyError(method, err, p, md)
+		return err
+	}
+}
+
+// StreamClientInterceptor is like UnaryClientInterceptor for streaming calls.
+// The span is finished when the stream ends or, for calls without server
+// streaming, when the response is received.
+func StreamClientInterceptor(notifier *gobrake.Notifier, opt *Options) grpc.StreamClientInterceptor {
+	in := newInterceptor(notifier, opt)
+	return func(
+		ctx context.Context, desc *grpc.StreamDesc,
+		cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		ctx, span := gobrake.ContextMetric(ctx).Start(ctx, "grpc.client")
+		p := new(peer.Peer)
+		cs, err := streamer(ctx, desc, cc, method, append(opts, grpc.Peer(p))...)
+		if err != nil {
+			span.Finish()
+			md, _ := metadata.FromOutgoingContext(ctx)
+			in.notifyError(method, err, p, md)
+			return nil, err
+		}
+		return &clientStream{
+			ClientStream: cs,
+			ctx:          ctx,
+			method:       method,
+			span:         span,
+			in:           in,
+			peer:         p,
+
+			serverStreams: desc.ServerStreams,
+		}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	method string
+	span   gobrake.Span
+	in     *interceptor
+	peer   *peer.Peer // set by grpc.Peer when the stream ends
+	done   bool
+
+	serverStreams bool
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if s.done || err == nil && s.serverStreams {
+		return err
+	}
+	s.done = true
+	s.span.Finish()
+	if err != nil && err != io.EOF {
+		md, _ := metadata.FromOutgoingContext(s.ctx)
+		s.in.notifyError(s.method, err, s.peer, md)
+	}
+	return err
+}
+
+func (in *interceptor) notifyServer(
+	ctx context.Context, metric *gobrake.RouteMetric, err error, notify bool,
+) {
+	code := status.Code(err)
+	if notify {
+		p, md := serverPeer(ctx)
+		in.notifyError(metric.Route, err, p, md)
+	}
+
+	metric.StatusCode = httpStatusFromCode(code)
+	metric.ContentType = "application/grpc"
+	_ = in.notifier.Routes.Notify(ctx, metric)
+}
+
+// notifyError reports err if its code is in ReportCodes. p and md are the
+// peer and the metadata of the call, incoming ones on the server side and
+// outgoing ones on the client side.
+func (in *interceptor) notifyError(
+	method string, err error, p *peer.Peer, md metadata.MD,
+) {
+	if err == nil || !in.report[status.Code(err)] {
+		return
+	}
+	notice := in.notifier.Notice(err, nil, 2)
+	in.notify(method, notice, status.Code(err), p, md)
+}
+
+func (in *interceptor) notifyPanic(ctx context.Context, method string, v interface{}) {
+	// Skip this function, the deferred function and runtime.gopanic.
+	notice := in.notifier.Notice(v, nil, 3)
+	notice.Context["severity"] = "critical"
+	p, md := serverPeer(ctx)
+	in.notify(method, notice, codes.Internal, p, md)
+}
+
+func (in *interceptor) notify(
+	method string, notice *gobrake.Notice, code codes.Code,
+	p *peer.Peer, md metadata.MD,
+) {
+	notice.Context["route"] = method
+	notice.Context["grpcCode"] = code.String()
+	setPeer(notice, p, md)
+	in.notifier.Notify(notice, nil)
+}
+
+// serverPeer returns the peer and the incoming metadata of a server call.
+func serverPeer(ctx context.Context) (*peer.Peer, metadata.MD) {
+	p, _ := peer.FromContext(ctx)
+	md, _ := metadata.FromIncomingContext(ctx)
+	return p, md
+}
+
+// Metadata keys with credentials. Their values are not reported.
+var sensitiveMetadata = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+}
+
+// setPeer adds the peer address and the metadata to the notice env. Values
+// of credential keys, e.g. authorization, are replaced with [Filtered].
+// Other keys can be filtered with NotifierOptions.KeysBlocklist.
+func setPeer(notice *gobrake.Notice, p *peer.Peer, md metadata.MD) {
+	if p != nil && p.Addr != nil {
+		notice.Env["peer"] = p.Addr.String()
+	}
+
+	for k, v := range md {
+		if sensitiveMetadata[k] {
+			notice.Env[k] = "[Filtered]"
+			continue
+		}
+		if len(v) == 1 {
+			notice.Env[k] = v[0]
+		} else {
+			notice.Env[k] = v
+		}
+	}
+	if ua := md.Get("user-agent"); len(ua) > 0 {
+		notice.Context["userAgent"] = ua[0]
+	}
+}
+
+// httpStatusFromCode maps gRPC status codes to HTTP status codes like
+// grpc-gateway does.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.Unknown:
+		return http.StatusInternalServerError
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Aborted:
+		return http.StatusConflict
+	case codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Internal:
+		return http.StatusInternalServerError
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DataLoss:
+		return http.StatusInternalServerError
+	}
+	return http.StatusInternalServerError
+}
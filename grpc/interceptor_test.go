@@ -0,0 +1,402 @@
+package grpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	grpcbrake "github.com/airbrake/gobrake/v4/grpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPC(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "grpc")
+}
+
+// healthServer behaves according to the requested service name.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	mu      sync.Mutex
+	metrics []*gobrake.RouteMetric
+}
+
+func (s *healthServer) handle(ctx context.Context, service string) error {
+	s.mu.Lock()
+	s.metrics = append(s.metrics, gobrake.ContextRouteMetric(ctx))
+	s.mu.Unlock()
+
+	switch service {
+	case "panic":
+		panic("boom")
+	case "internal":
+		return status.Error(codes.Internal, "db is down")
+	case "not-found":
+		return status.Error(codes.NotFound, "no such service")
+	}
+	return nil
+}
+
+func (s *healthServer) Check(
+	ctx context.Context, req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	if err := s.handle(ctx, req.Service); err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{
+		Status: healthpb.HealthCheckResponse_SERVING,
+	}, nil
+}
+
+func (s *healthServer) Watch(
+	req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer,
+) error {
+	if err := s.handle(stream.Context(), req.Service); err != nil {
+		return err
+	}
+	return stream.Send(&healthpb.HealthCheckResponse{
+		Status: healthpb.HealthCheckResponse_SERVING,
+	})
+}
+
+var _ = Describe("interceptors", func() {
+	var notifier *gobrake.Notifier
+	var opt *grpcbrake.Options
+	var health *healthServer
+	var server *grpc.Server
+	var conn *grpc.ClientConn
+	var client healthpb.HealthClient
+
+	var mu sync.Mutex
+	var sentNotices []*gobrake.Notice
+	var metrics []*gobrake.RouteMetric
+
+	BeforeEach(func() {
+		opt = nil
+		sentNotices = nil
+		metrics = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotices = append(sentNotices, notice)
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		httpServer := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             httpServer.URL,
+			RemoteConfigHost: httpServer.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			mu.Lock()
+			metrics = append(metrics, m)
+			mu.Unlock()
+			return nil
+		})
+	})
+
+	JustBeforeEach(func() {
+		lis := bufconn.Listen(1 << 20)
+		health = new(healthServer)
+		server = grpc.NewServer(
+			grpc.UnaryInterceptor(grpcbrake.UnaryServerInterceptor(notifier, opt)),
+			grpc.StreamInterceptor(grpcbrake.StreamServerInterceptor(notifier, opt)),
+		)
+		healthpb.RegisterHealthServer(server, health)
+		go func() {
+			_ = server.Serve(lis)
+		}()
+
+		var err error
+		conn, err = grpc.Dial("bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		client = healthpb.NewHealthClient(conn)
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).NotTo(HaveOccurred())
+		server.Stop()
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	check := func(service string) error {
+		ctx := metadata.AppendToOutgoingContext(context.Background(),
+			"x-request-id", "42", "authorization", "Bearer secret")
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		notifier.Flush()
+		return err
+	}
+
+	watch := func(service string) error {
+		stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = stream.Recv()
+		notifier.Flush()
+		return err
+	}
+
+	notices := func() []*gobrake.Notice {
+		mu.Lock()
+		defer mu.Unlock()
+		return sentNotices
+	}
+
+	lastMetric := func() *gobrake.RouteMetric {
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(metrics).NotTo(BeEmpty())
+		return metrics[len(metrics)-1]
+	}
+
+	It("reports unary calls", func() {
+		Expect(check("")).To(Succeed())
+
+		metric := lastMetric()
+		Expect(metric.Method).To(Equal("GRPC"))
+		Expect(metric.Route).To(Equal("/grpc.health.v1.Health/Check"))
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+		Expect(metric.ContentType).To(Equal("application/grpc"))
+		Expect(health.metrics).To(ConsistOf(BeIdenticalTo(metric)))
+		Expect(notices()).To(BeEmpty())
+	})
+
+	It("reports streaming calls", func() {
+		Expect(watch("")).To(Succeed())
+
+		metric := lastMetric()
+		Expect(metric.Route).To(Equal("/grpc.health.v1.Health/Watch"))
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+		Expect(health.metrics).To(ConsistOf(BeIdenticalTo(metric)))
+	})
+
+	It("reports panics with peer and metadata", func() {
+		err := check("panic")
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		Expect(lastMetric().StatusCode).To(Equal(http.StatusInternalServerError))
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Errors[0].Message).To(Equal("boom"))
+		Expect(notice.Errors[0].Backtrace[0].File).To(HaveSuffix("interceptor_test.go"))
+		Expect(notice.Context["severity"]).To(Equal("critical"))
+		Expect(notice.Context["route"]).To(Equal("/grpc.health.v1.Health/Check"))
+		Expect(notice.Env["peer"]).To(Equal("bufconn"))
+		Expect(notice.Env["x-request-id"]).To(Equal("42"))
+		Expect(notice.Env["authorization"]).To(Equal("[Filtered]"))
+	})
+
+	It("reports panics of streaming calls", func() {
+		err := watch("panic")
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+
+		Expect(notices()).To(HaveLen(1))
+		Expect(notices()[0].Context["route"]).To(Equal("/grpc.health.v1.Health/Watch"))
+	})
+
+	It("reports errors with ReportCodes", func() {
+		err := check("internal")
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		Expect(lastMetric().StatusCode).To(Equal(http.StatusInternalServerError))
+
+		Expect(notices()).To(HaveLen(1))
+		Expect(notices()[0].Errors[0].Message).To(ContainSubstring("db is down"))
+		Expect(notices()[0].Context["grpcCode"]).To(Equal("Internal"))
+	})
+
+	It("doesn't report other errors", func() {
+		err := check("not-found")
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+		Expect(lastMetric().StatusCode).To(Equal(http.StatusNotFound))
+		Expect(notices()).To(BeEmpty())
+	})
+
+	Context("with ReportCodes", func() {
+		BeforeEach(func() {
+			opt = &grpcbrake.Options{
+				ReportCodes: []codes.Code{codes.NotFound},
+			}
+		})
+
+		It("reports configured codes", func() {
+			Expect(check("internal")).NotTo(Succeed())
+			Expect(notices()).To(BeEmpty())
+
+			Expect(check("not-found")).NotTo(Succeed())
+			Expect(notices()).To(HaveLen(1))
+			Expect(notices()[0].Context["grpcCode"]).To(Equal("NotFound"))
+		})
+	})
+})
+
+var _ = Describe("client interceptors", func() {
+	var notifier *gobrake.Notifier
+	var server *grpc.Server
+	var conn *grpc.ClientConn
+	var client healthpb.HealthClient
+
+	var mu sync.Mutex
+	var sentNotices []*gobrake.Notice
+
+	BeforeEach(func() {
+		sentNotices = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotices = append(sentNotices, notice)
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		httpServer := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             httpServer.URL,
+			RemoteConfigHost: httpServer.URL,
+		})
+
+		lis := bufconn.Listen(1 << 20)
+		server = grpc.NewServer()
+		healthpb.RegisterHealthServer(server, new(healthServer))
+		go func() {
+			_ = server.Serve(lis)
+		}()
+
+		var err error
+		conn, err = grpc.Dial("bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithUnaryInterceptor(grpcbrake.UnaryClientInterceptor(notifier, nil)),
+			grpc.WithStreamInterceptor(grpcbrake.StreamClientInterceptor(notifier, nil)),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		client = healthpb.NewHealthClient(conn)
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).NotTo(HaveOccurred())
+		server.Stop()
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	notices := func() []*gobrake.Notice {
+		mu.Lock()
+		defer mu.Unlock()
+		return sentNotices
+	}
+
+	It("reports errors of unary calls", func() {
+		ctx, _ := gobrake.NewRouteMetric(context.Background(), "GET", "/")
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "internal"})
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		notifier.Flush()
+
+		Expect(notices()).To(HaveLen(1))
+		Expect(notices()[0].Context["route"]).To(Equal("/grpc.health.v1.Health/Check"))
+		Expect(notices()[0].Context["grpcCode"]).To(Equal("Internal"))
+	})
+
+	It("reports outgoing metadata and server address inside server calls", func() {
+		// A client call made by a server handler.
+		ctx := metadata.NewIncomingContext(context.Background(),
+			metadata.Pairs("x-incoming", "1"))
+		ctx = peer.NewContext(ctx, &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234},
+		})
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			"x-outgoing", "2", "authorization", "secret")
+
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "internal"})
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		notifier.Flush()
+
+		Expect(notices()).To(HaveLen(1))
+		notice := notices()[0]
+		Expect(notice.Env["peer"]).To(Equal("bufconn"))
+		Expect(notice.Env["x-outgoing"]).To(Equal("2"))
+		Expect(notice.Env["authorization"]).To(Equal("[Filtered]"))
+		Expect(notice.Env).NotTo(HaveKey("x-incoming"))
+	})
+
+	It("reports errors of streaming calls", func() {
+		stream, err := client.Watch(context.Background(),
+			&healthpb.HealthCheckRequest{Service: "internal"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = stream.Recv()
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+		notifier.Flush()
+
+		Expect(notices()).To(HaveLen(1))
+		Expect(notices()[0].Context["route"]).To(Equal("/grpc.health.v1.Health/Watch"))
+		Expect(notices()[0].Env["peer"]).To(Equal("bufconn"))
+	})
+
+	It("doesn't report successful streams", func() {
+		stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = stream.Recv()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = stream.Recv()
+		Expect(err).To(HaveOccurred())
+		notifier.Flush()
+
+		Expect(notices()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,87 @@
+// Package http provides net/http middleware that reports panics to Airbrake
+// and collects route stats.
+package http
+
+import (
+	"net/http"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+type Options struct {
+	// RouteName returns the route name of the request for route stats. It is
+	// called after the request is handled. Default is the URL path
+	// normalized with gobrake.NormalizePath, e.g. /users/:id.
+	RouteName func(*http.Request) string
+}
+
+// Middleware reports panics of HTTP handlers as critical notices and
+// reports route stats of every request.
+type Middleware struct {
+	notifier *gobrake.Notifier
+	opt      *Options
+}
+
+func New(notifier *gobrake.Notifier, opt *Options) *Middleware {
+	if opt == nil {
+		opt = new(Options)
+	}
+	if opt.RouteName == nil {
+		opt.RouteName = func(req *http.Request) string {
+			return gobrake.NormalizePath(req.URL.Path)
+		}
+	}
+	return &Middleware{
+		notifier: notifier,
+		opt:      opt,
+	}
+}
+
+// Handler wraps next. Panics are recovered and answered with 500 Internal
+// Server Error unless the response was already started.
+// http.ErrAbortHandler is not reported and is re-panicked.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	if m.notifier == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The route name is known only after the request is handled, so
+		// queries that run during the request see the normalized path.
+		c, metric := gobrake.NewRouteMetric(
+			req.Context(), req.Method, gobrake.NormalizePath(req.URL.Path))
+		c = gobrake.ContextWithRequest(c, req)
+		req = req.WithContext(c)
+
+		rw := newResponseWriter(w)
+
+		defer func() {
+			v := recover()
+			if v != nil && v != http.ErrAbortHandler {
+				m.notifyPanic(v, req, metric)
+				if !rw.wroteHeader {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError),
+						http.StatusInternalServerError)
+				}
+			}
+
+			metric.Route = m.opt.RouteName(req)
+			metric.StatusCode = rw.status
+			metric.ContentType = rw.contentType()
+			_ = m.notifier.Routes.Notify(c, metric)
+
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+		}()
+
+		next.ServeHTTP(rw.wrap(), req)
+	})
+}
+
+func (m *Middleware) notifyPanic(v interface{}, req *http.Request, metric *gobrake.RouteMetric) {
+	// Skip this function, the deferred function and runtime.gopanic.
+	notice := m.notifier.Notice(v, req, 3)
+	notice.Context["severity"] = "critical"
+	notice.Context["route"] = m.opt.RouteName(req)
+	m.notifier.Notify(notice, req)
+}
@@ -0,0 +1,48 @@
+//go:build go1.20
+
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	httpbrake "github.com/airbrake/gobrake/v4/http"
+)
+
+var _ = Describe("Middleware with http.ResponseController", func() {
+	var notifier *gobrake.Notifier
+
+	BeforeEach(func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		}))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("unwraps the response writer", func() {
+		h := httpbrake.New(notifier, nil).Handler(http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				err := http.NewResponseController(w).Flush()
+				Expect(err).To(BeNil())
+			},
+		))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/users/123", nil))
+		Expect(rec.Flushed).To(BeTrue())
+	})
+})
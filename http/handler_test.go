@@ -0,0 +1,212 @@
+package http_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	httpbrake "github.com/airbrake/gobrake/v4/http"
+)
+
+func TestHTTP(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "http")
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+var _ = Describe("Middleware", func() {
+	var notifier *gobrake.Notifier
+	var middleware *httpbrake.Middleware
+	var opt *httpbrake.Options
+
+	var mu sync.Mutex
+	var sentNotice *gobrake.Notice
+	var metric *gobrake.RouteMetric
+
+	BeforeEach(func() {
+		sentNotice = nil
+		metric = nil
+		opt = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotice = notice
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			metric = m
+			return nil
+		})
+	})
+
+	JustBeforeEach(func() {
+		middleware = httpbrake.New(notifier, opt)
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	serve := func(h http.HandlerFunc, w http.ResponseWriter) {
+		req := httptest.NewRequest("GET", "/users/123", nil)
+		middleware.Handler(h).ServeHTTP(w, req)
+		notifier.Flush()
+	}
+
+	It("reports route stats", func() {
+		var reqMetric *gobrake.RouteMetric
+		var reqRequest *http.Request
+		rec := httptest.NewRecorder()
+		serve(func(w http.ResponseWriter, req *http.Request) {
+			reqMetric = gobrake.ContextRouteMetric(req.Context())
+			reqRequest = gobrake.ContextRequest(req.Context())
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+		}, rec)
+
+		Expect(metric).NotTo(BeNil())
+		Expect(reqMetric).To(BeIdenticalTo(metric))
+		Expect(reqRequest).NotTo(BeNil())
+		Expect(metric.Method).To(Equal("GET"))
+		Expect(metric.Route).To(Equal("/users/:id"))
+		Expect(metric.StatusCode).To(Equal(http.StatusCreated))
+		Expect(metric.ContentType).To(Equal("application/json"))
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+	})
+
+	It("records sniffed content type and implicit status", func() {
+		serve(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("<html></html>"))
+		}, httptest.NewRecorder())
+
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+		Expect(metric.ContentType).To(Equal("text/html; charset=utf-8"))
+	})
+
+	Context("with RouteName", func() {
+		BeforeEach(func() {
+			opt = &httpbrake.Options{
+				RouteName: func(req *http.Request) string {
+					return "/users/:id"
+				},
+			}
+		})
+
+		It("uses route name", func() {
+			serve(func(w http.ResponseWriter, req *http.Request) {}, httptest.NewRecorder())
+			Expect(metric.Route).To(Equal("/users/:id"))
+		})
+	})
+
+	It("recovers panics", func() {
+		rec := httptest.NewRecorder()
+		serve(func(w http.ResponseWriter, req *http.Request) {
+			panic("boom")
+		}, rec)
+
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotice).NotTo(BeNil())
+		Expect(sentNotice.Errors[0].Message).To(Equal("boom"))
+		Expect(sentNotice.Errors[0].Backtrace[0].File).To(HaveSuffix("handler_test.go"))
+		Expect(sentNotice.Context["severity"]).To(Equal("critical"))
+		Expect(sentNotice.Context["url"]).To(Equal("/users/123"))
+		Expect(sentNotice.Context["route"]).To(Equal("/users/:id"))
+	})
+
+	It("reports written status on panics after the response was started", func() {
+		rec := httptest.NewRecorder()
+		serve(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			panic("boom")
+		}, rec)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotice).NotTo(BeNil())
+		Expect(sentNotice.Errors[0].Message).To(Equal("boom"))
+	})
+
+	It("re-panics http.ErrAbortHandler", func() {
+		var v interface{}
+		func() {
+			defer func() {
+				v = recover()
+			}()
+			serve(func(w http.ResponseWriter, req *http.Request) {
+				panic(http.ErrAbortHandler)
+			}, httptest.NewRecorder())
+		}()
+		Expect(v).To(Equal(http.ErrAbortHandler))
+
+		Expect(metric).NotTo(BeNil())
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotice).To(BeNil())
+	})
+
+	It("preserves optional interfaces", func() {
+		var flusher, hijacker, pusher bool
+		h := func(w http.ResponseWriter, req *http.Request) {
+			_, flusher = w.(http.Flusher)
+			_, hijacker = w.(http.Hijacker)
+			_, pusher = w.(http.Pusher)
+		}
+
+		serve(h, httptest.NewRecorder())
+		Expect(flusher).To(BeTrue())
+		Expect(hijacker).To(BeFalse())
+		Expect(pusher).To(BeFalse())
+
+		serve(h, hijackRecorder{httptest.NewRecorder()})
+		Expect(flusher).To(BeTrue())
+		Expect(hijacker).To(BeTrue())
+		Expect(pusher).To(BeFalse())
+	})
+})
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+type unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// responseWriter records the status code and content type of a response.
+type responseWriter struct {
+	http.ResponseWriter
+
+	status      int
+	sniffed     string
+	wroteHeader bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+	}
+}
+
+// wrap returns a writer that implements only the optional interfaces
+// implemented by the original writer.
+func (w *responseWriter) wrap() http.ResponseWriter {
+	_, f := w.ResponseWriter.(http.Flusher)
+	_, h := w.ResponseWriter.(http.Hijacker)
+	_, p := w.ResponseWriter.(http.Pusher)
+
+	switch {
+	case f && h && p:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{w, w, w, w, w}
+	case f && h:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Hijacker
+		}{w, w, w, w}
+	case f && p:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+			http.Pusher
+		}{w, w, w, w}
+	case h && p:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+			http.Pusher
+		}{w, w, w, w}
+	case f:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Flusher
+		}{w, w, w}
+	case h:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Hijacker
+		}{w, w, w}
+	case p:
+		return struct {
+			http.ResponseWriter
+			unwrapper
+			http.Pusher
+		}{w, w, w}
+	}
+	return struct {
+		http.ResponseWriter
+		unwrapper
+	}{w, w}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		// Like net/http, sniff the content type of the first write.
+		if w.Header().Get("Content-Type") == "" && len(b) > 0 {
+			w.sniffed = http.DetectContentType(b)
+		}
+		w.wroteHeader = true
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+// Unwrap allows http.ResponseController to access the original writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) contentType() string {
+	if ct := w.Header().Get("Content-Type"); ct != "" {
+		return ct
+	}
+	return w.sniffed
+}
@@ -0,0 +1,142 @@
+package gobrake
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// statusCoder is implemented by errors that carry an HTTP status code.
+type statusCoder interface {
+	StatusCode() int
+}
+
+// ignoreRules decides whether an error is ignored before a notice is built,
+// so ignored errors don't pay for backtraces and code hunks.
+type ignoreRules struct {
+	env         bool
+	types       map[string]struct{}
+	messages    []*regexp.Regexp
+	errors      []error
+	statusCodes map[int]struct{}
+}
+
+func newIgnoreRules(opt *NotifierOptions) *ignoreRules {
+	r := new(ignoreRules)
+
+	for _, env := range opt.IgnoreEnvironments {
+		if env == opt.Environment {
+			r.env = true
+			break
+		}
+	}
+
+	if len(opt.IgnoreErrorTypes) > 0 {
+		r.types = make(map[string]struct{}, len(opt.IgnoreErrorTypes))
+		for _, typ := range opt.IgnoreErrorTypes {
+			r.types[typ] = struct{}{}
+		}
+	}
+
+	r.messages = opt.IgnoreMessages
+	r.errors = opt.IgnoreErrors
+
+	if len(opt.IgnoreStatusCodes) > 0 {
+		r.statusCodes = make(map[int]struct{}, len(opt.IgnoreStatusCodes))
+		for _, code := range opt.IgnoreStatusCodes {
+			r.statusCodes[code] = struct{}{}
+		}
+	}
+
+	return r
+}
+
+func (r *ignoreRules) empty() bool {
+	return !r.env && r.types == nil && r.messages == nil &&
+		r.errors == nil && r.statusCodes == nil
+}
+
+// ignored reports whether e must not be reported.
+func (r *ignoreRules) ignored(e interface{}) bool {
+	if r.env {
+		return true
+	}
+	if notice, ok := e.(*Notice); ok {
+		return r.ignoredNotice(notice)
+	}
+	return r.ignoredValue(e)
+}
+
+func (r *ignoreRules) ignoredNotice(notice *Notice) bool {
+	if r.env {
+		return true
+	}
+	if notice.err != nil && r.ignoredValue(notice.err) {
+		return true
+	}
+	if len(notice.Errors) == 0 {
+		return false
+	}
+	e := notice.Errors[0]
+	return r.matchType(e.Type) || r.matchMessage(e.Message)
+}
+
+func (r *ignoreRules) ignoredValue(e interface{}) bool {
+	if r.matchType(getTypeName(e)) {
+		return true
+	}
+
+	if len(r.messages) > 0 && r.matchMessage(fmt.Sprint(e)) {
+		return true
+	}
+
+	err, ok := e.(error)
+	if !ok {
+		return false
+	}
+
+	for _, target := range r.errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if r.matchType(fmt.Sprintf("%T", err)) {
+			return true
+		}
+		if sc, ok := err.(statusCoder); ok && r.matchStatusCode(sc.StatusCode()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *ignoreRules) matchType(typ string) bool {
+	_, ok := r.types[typ]
+	return ok
+}
+
+func (r *ignoreRules) matchMessage(msg string) bool {
+	for _, re := range r.messages {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ignoreRules) matchStatusCode(code int) bool {
+	_, ok := r.statusCodes[code]
+	return ok
+}
+
+func newIgnoreFilter(r *ignoreRules) func(*Notice) *Notice {
+	return func(notice *Notice) *Notice {
+		if r.ignoredNotice(notice) {
+			return nil
+		}
+		return notice
+	}
+}
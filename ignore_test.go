@@ -0,0 +1,175 @@
+package gobrake_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+type statusError struct {
+	code int
+}
+
+func (e statusError) Error() string {
+	return http.StatusText(e.code)
+}
+
+func (e statusError) StatusCode() int {
+	return e.code
+}
+
+var _ = Describe("Ignore options", func() {
+	var notifier *gobrake.Notifier
+	var opt *gobrake.NotifierOptions
+	var sent int
+	var filtered int
+
+	BeforeEach(func() {
+		sent = 0
+		filtered = 0
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			sent++
+			w.WriteHeader(http.StatusCreated)
+			_, err := w.Write([]byte(`{"id":"123"}`))
+			Expect(err).To(BeNil())
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		configServer := newConfigServer()
+
+		opt = &gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: configServer.URL,
+			Environment:      "production",
+		}
+	})
+
+	JustBeforeEach(func() {
+		notifier = gobrake.NewNotifierWithOptions(opt)
+		notifier.AddFilter(func(notice *gobrake.Notice) *gobrake.Notice {
+			filtered++
+			return notice
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	notify := func(e interface{}) {
+		notifier.Notify(e, nil)
+		notifier.Flush()
+	}
+
+	Context("IgnoreErrorTypes", func() {
+		BeforeEach(func() {
+			opt.IgnoreErrorTypes = []string{"*errors.errorString"}
+		})
+
+		It("ignores errors of the type before building notice", func() {
+			notify(errors.New("oops"))
+			notify(fmt.Errorf("wrapped: %w", errors.New("oops")))
+			Expect(filtered).To(Equal(0))
+			Expect(sent).To(Equal(0))
+
+			notify("oops")
+			Expect(sent).To(Equal(1))
+		})
+	})
+
+	Context("IgnoreMessages", func() {
+		BeforeEach(func() {
+			opt.IgnoreMessages = []*regexp.Regexp{regexp.MustCompile("^context canceled$")}
+		})
+
+		It("ignores errors with matching message", func() {
+			notify(context.Canceled)
+			Expect(sent).To(Equal(0))
+
+			notify(errors.New("request canceled: context canceled"))
+			Expect(sent).To(Equal(1))
+		})
+
+		It("ignores notices sent directly", func() {
+			notice := notifier.Notice(context.Canceled, nil, 0)
+			_, err := notifier.SendNotice(notice)
+			Expect(err).To(BeNil())
+			Expect(sent).To(Equal(0))
+		})
+	})
+
+	Context("IgnoreErrors", func() {
+		BeforeEach(func() {
+			opt.IgnoreErrors = []error{io.EOF}
+		})
+
+		It("ignores errors wrapping sentinel errors", func() {
+			notify(fmt.Errorf("read body: %w", io.EOF))
+			Expect(sent).To(Equal(0))
+
+			notify(io.ErrUnexpectedEOF)
+			Expect(sent).To(Equal(1))
+		})
+
+		It("ignores notices sent directly", func() {
+			notice := notifier.Notice(fmt.Errorf("read body: %w", io.EOF), nil, 0)
+			_, err := notifier.SendNotice(notice)
+			Expect(err).To(BeNil())
+			Expect(sent).To(Equal(0))
+		})
+	})
+
+	Context("IgnoreStatusCodes", func() {
+		BeforeEach(func() {
+			opt.IgnoreStatusCodes = []int{http.StatusNotFound}
+		})
+
+		It("ignores errors with the status code", func() {
+			notify(fmt.Errorf("get user: %w", statusError{http.StatusNotFound}))
+			Expect(sent).To(Equal(0))
+
+			notify(statusError{http.StatusBadGateway})
+			Expect(sent).To(Equal(1))
+		})
+
+		It("ignores notices sent directly", func() {
+			notice := notifier.Notice(fmt.Errorf("get user: %w", statusError{http.StatusNotFound}), nil, 0)
+			_, err := notifier.SendNotice(notice)
+			Expect(err).To(BeNil())
+			Expect(sent).To(Equal(0))
+		})
+	})
+
+	Context("IgnoreEnvironments", func() {
+		BeforeEach(func() {
+			opt.IgnoreEnvironments = []string{"development", "production"}
+		})
+
+		It("ignores all errors", func() {
+			notify("oops")
+			Expect(filtered).To(Equal(0))
+			Expect(sent).To(Equal(0))
+		})
+
+		It("ignores panics and re-panics", func() {
+			defer func() {
+				Expect(recover()).To(Equal("oops"))
+				Expect(sent).To(Equal(0))
+			}()
+
+			defer notifier.NotifyOnPanic()
+			panic("oops")
+		})
+	})
+})
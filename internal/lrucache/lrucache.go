@@ -9,24 +9,59 @@ import (
 type entry struct {
 	key     string
 	value   interface{}
+	size    int
 	addedAt time.Time
 }
 
+type Options struct {
+	// Maximum number of entries. Zero means no limit.
+	MaxLen int
+
+	// Maximum total size of entries as reported to SetWithSize.
+	// Zero means no limit.
+	MaxSize int
+
+	// Entries older than TTL are expired. Zero means entries never expire.
+	TTL time.Duration
+}
+
+// Stats are cache counters since the cache was created.
+type Stats struct {
+	Hits   uint64
+	Misses uint64
+	Len    int
+	Size   int
+}
+
 type Cache struct {
 	mu sync.Mutex
 
 	list  *list.List
 	table map[string]*list.Element
 
-	maxLen int
+	maxLen  int
+	maxSize int
+	ttl     time.Duration
+
+	size   int
+	hits   uint64
+	misses uint64
 }
 
 func New(maxLen int) *Cache {
+	return NewWithOptions(&Options{
+		MaxLen: maxLen,
+	})
+}
+
+func NewWithOptions(opt *Options) *Cache {
 	return &Cache{
 		list:  list.New(),
-		table: make(map[string]*list.Element, maxLen),
+		table: make(map[string]*list.Element, opt.MaxLen),
 
-		maxLen: maxLen,
+		maxLen:  opt.MaxLen,
+		maxSize: opt.MaxSize,
+		ttl:     opt.TTL,
 	}
 }
 
@@ -39,37 +74,85 @@ func (c *Cache) get(key string) (interface{}, bool) {
 
 	el := c.table[key]
 	if el == nil {
+		c.misses++
 		c.mu.Unlock()
 		return nil, false
 	}
 
 	entry := el.Value.(*entry)
+	if c.ttl > 0 && time.Since(entry.addedAt) > c.ttl {
+		c.deleteElement(el)
+		c.misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+
 	c.list.MoveToFront(el)
 	value := entry.value
+	c.hits++
 	c.mu.Unlock()
 	return value, true
 }
 
 func (c *Cache) Set(key string, value interface{}) {
+	c.SetWithSize(key, value, 0)
+}
+
+// SetWithSize adds the value to the cache. The size is used to enforce
+// MaxSize. Values larger than MaxSize are not cached.
+func (c *Cache) SetWithSize(key string, value interface{}, size int) {
 	c.mu.Lock()
+	if c.maxSize > 0 && size > c.maxSize {
+		if el := c.table[key]; el != nil {
+			c.deleteElement(el)
+		}
+		c.mu.Unlock()
+		return
+	}
+
 	if el := c.table[key]; el != nil {
 		entry := el.Value.(*entry)
 		entry.value = value
+		c.size += size - entry.size
+		entry.size = size
 		c.promote(el, entry)
+		c.check()
 	} else {
-		c.addNew(key, value)
+		c.addNew(key, value, size)
+	}
+	c.mu.Unlock()
+}
+
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	if el := c.table[key]; el != nil {
+		c.deleteElement(el)
 	}
 	c.mu.Unlock()
 }
 
-func (c *Cache) addNew(key string, value interface{}) {
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	stats := Stats{
+		Hits:   c.hits,
+		Misses: c.misses,
+		Len:    c.list.Len(),
+		Size:   c.size,
+	}
+	c.mu.Unlock()
+	return stats
+}
+
+func (c *Cache) addNew(key string, value interface{}, size int) {
 	newEntry := &entry{
 		key:     key,
 		value:   value,
+		size:    size,
 		addedAt: time.Now(),
 	}
 	element := c.list.PushFront(newEntry)
 	c.table[key] = element
+	c.size += size
 	c.check()
 }
 
@@ -79,13 +162,17 @@ func (c *Cache) promote(el *list.Element, entry *entry) {
 }
 
 func (c *Cache) deleteElement(el *list.Element) {
+	entry := el.Value.(*entry)
 	c.list.Remove(el)
-	delete(c.table, el.Value.(*entry).key)
+	delete(c.table, entry.key)
+	c.size -= entry.size
 }
 
 func (c *Cache) check() {
-	for c.list.Len() > c.maxLen {
-		el := c.list.Back()
-		c.deleteElement(el)
+	for c.maxLen > 0 && c.list.Len() > c.maxLen {
+		c.deleteElement(c.list.Back())
+	}
+	for c.maxSize > 0 && c.size > c.maxSize {
+		c.deleteElement(c.list.Back())
 	}
 }
@@ -0,0 +1,73 @@
+package lrucache_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4/internal/lrucache"
+)
+
+func TestLRUCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "lrucache")
+}
+
+var _ = Describe("Cache", func() {
+	It("evicts least recently used entries", func() {
+		c := lrucache.New(2)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		_, _ = c.Get("a")
+		c.Set("c", 3)
+
+		_, ok := c.Get("b")
+		Expect(ok).To(BeFalse())
+		v, ok := c.Get("a")
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal(1))
+	})
+
+	It("limits total size", func() {
+		c := lrucache.NewWithOptions(&lrucache.Options{MaxSize: 10})
+		c.SetWithSize("a", 1, 6)
+		c.SetWithSize("b", 2, 4)
+		c.SetWithSize("c", 3, 5)
+
+		_, ok := c.Get("a")
+		Expect(ok).To(BeFalse())
+		Expect(c.Stats().Size).To(Equal(9))
+
+		c.SetWithSize("d", 4, 11)
+		_, ok = c.Get("d")
+		Expect(ok).To(BeFalse())
+		Expect(c.Stats().Len).To(Equal(2))
+	})
+
+	It("expires entries after TTL", func() {
+		c := lrucache.NewWithOptions(&lrucache.Options{TTL: 10 * time.Millisecond})
+		c.Set("a", 1)
+
+		_, ok := c.Get("a")
+		Expect(ok).To(BeTrue())
+
+		time.Sleep(20 * time.Millisecond)
+		_, ok = c.Get("a")
+		Expect(ok).To(BeFalse())
+		Expect(c.Stats().Len).To(Equal(0))
+	})
+
+	It("counts hits and misses", func() {
+		c := lrucache.New(10)
+		c.Set("a", 1)
+		_, _ = c.Get("a")
+		_, _ = c.Get("a")
+		_, _ = c.Get("b")
+
+		stats := c.Stats()
+		Expect(stats.Hits).To(Equal(uint64(2)))
+		Expect(stats.Misses).To(Equal(uint64(1)))
+	})
+})
@@ -0,0 +1,59 @@
+package gobrake
+
+import (
+	"sync/atomic"
+)
+
+const (
+	defaultAPMMaxKeys = 10000
+
+	// otherKey replaces fields of APM keys over the APMMaxKeys limit.
+	otherKey = "__other__"
+)
+
+// keyLimit caps the number of distinct keys an APM aggregator keeps in a
+// flush window. Except for folded it is guarded by the aggregator mutex.
+type keyLimit struct {
+	name string
+	max  int
+
+	dropped map[interface{}]struct{}
+	folded  uint64 // atomic
+}
+
+func newKeyLimit(name string, opt *NotifierOptions) *keyLimit {
+	return &keyLimit{
+		name: name,
+		max:  opt.APMMaxKeys,
+	}
+}
+
+// overflow reports whether key must be folded into the __other__ key because
+// the aggregator already has n keys. Every distinct key dropped in the flush
+// window is counted once and the first one is logged. At most max dropped
+// keys are tracked per window, later ones are folded without being counted.
+func (l *keyLimit) overflow(n int, key interface{}) bool {
+	if n < l.max {
+		return false
+	}
+	if l.dropped == nil {
+		l.dropped = make(map[interface{}]struct{})
+		logger.Printf("%s: more than %d distinct keys, "+
+			"new keys are reported as %s until the next flush",
+			l.name, l.max, otherKey)
+	}
+	if _, ok := l.dropped[key]; !ok && len(l.dropped) < l.max {
+		l.dropped[key] = struct{}{}
+		atomic.AddUint64(&l.folded, 1)
+	}
+	return true
+}
+
+// reset starts a new flush window.
+func (l *keyLimit) reset() {
+	l.dropped = nil
+}
+
+func (l *keyLimit) count() uint64 {
+	return atomic.LoadUint64(&l.folded)
+}
@@ -0,0 +1,223 @@
+package gobrake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APMMaxKeys", func() {
+	type apmKey struct {
+		Method string `json:"method"`
+		Route  string `json:"route"`
+		Query  string `json:"query"`
+		Queue  string `json:"queue"`
+		Count  int    `json:"count"`
+	}
+
+	type apmOut struct {
+		Routes  []apmKey `json:"routes"`
+		Queries []apmKey `json:"queries"`
+		Queues  []apmKey `json:"queues"`
+	}
+
+	var notifier *Notifier
+	var origLogger *log.Logger
+	var logBuf *bytes.Buffer
+
+	var mu sync.Mutex
+	var sent map[string]*apmOut
+
+	BeforeEach(func() {
+		sent = make(map[string]*apmOut)
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			out := new(apmOut)
+			err = json.Unmarshal(b, out)
+			Expect(err).NotTo(HaveOccurred())
+
+			mu.Lock()
+			sent[req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]] = out
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		configServer := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+		origLogger = GetLogger()
+		logBuf = new(bytes.Buffer)
+		SetLogger(log.New(logBuf, "", 0))
+
+		notifier = NewNotifierWithOptions(&NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: configServer.URL,
+			APMMaxKeys:       2,
+		})
+	})
+
+	AfterEach(func() {
+		SetLogger(origLogger)
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("folds routes over the limit into __other__", func() {
+		for _, route := range []string{"/a", "/b", "/c", "/d", "/c", "/a"} {
+			c, metric := NewRouteMetric(context.TODO(), "GET", route)
+			metric.StatusCode = http.StatusOK
+			err := notifier.Routes.Notify(c, metric)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		notifier.Routes.Flush()
+
+		mu.Lock()
+		routes := sent["routes-stats"].Routes
+		mu.Unlock()
+		Expect(routes).To(ConsistOf(
+			apmKey{Method: "GET", Route: "/a", Count: 2},
+			apmKey{Method: "GET", Route: "/b", Count: 1},
+			apmKey{Method: "__other__", Route: "__other__", Count: 3},
+		))
+
+		stats := notifier.Stats()
+		Expect(stats.RoutesFolded).To(BeNumerically("==", 2))
+		Expect(stats.RouteBreakdownsFolded).To(BeNumerically("==", 2))
+		Expect(strings.Count(logBuf.String(), "routeStats: more than 2 distinct keys")).To(Equal(1))
+	})
+
+	It("keeps time and status code of folded routes", func() {
+		now := time.Now().UTC().Truncate(time.Minute)
+		for i, route := range []string{"/a", "/b", "/c", "/d"} {
+			c, metric := NewRouteMetric(context.TODO(), "GET", route)
+			metric.StatusCode = http.StatusOK + i
+			metric.startTime = now.Add(-time.Duration(i) * time.Hour)
+			err := notifier.Routes.Notify(c, metric)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		notifier.Routes.stats.mu.Lock()
+		var keys []routeKey
+		for key := range notifier.Routes.stats.m {
+			if key.Method == otherKey {
+				keys = append(keys, key)
+			}
+		}
+		notifier.Routes.stats.mu.Unlock()
+		Expect(keys).To(ConsistOf(
+			routeKey{
+				Method:     otherKey,
+				Route:      otherKey,
+				StatusCode: http.StatusOK + 2,
+				Time:       now.Add(-2 * time.Hour),
+			},
+			routeKey{
+				Method:     otherKey,
+				Route:      otherKey,
+				StatusCode: http.StatusOK + 3,
+				Time:       now.Add(-3 * time.Hour),
+			},
+		))
+	})
+
+	It("logs once per flush window", func() {
+		for i := 0; i < 2; i++ {
+			for _, route := range []string{"/a", "/b", "/c"} {
+				c, metric := NewRouteMetric(context.TODO(), "GET", route)
+				metric.StatusCode = http.StatusOK
+				_ = notifier.Routes.Notify(c, metric)
+			}
+			notifier.Routes.Flush()
+		}
+
+		Expect(notifier.Stats().RoutesFolded).To(BeNumerically("==", 2))
+		Expect(strings.Count(logBuf.String(), "routeStats: more than 2 distinct keys")).To(Equal(2))
+	})
+
+	It("folds queries over the limit into __other__", func() {
+		for _, query := range []string{"SELECT * FROM a", "SELECT * FROM b", "SELECT * FROM c"} {
+			err := notifier.Queries.Notify(context.TODO(), &QueryInfo{
+				Method:    "GET",
+				Route:     "/",
+				Query:     query,
+				StartTime: time.Now(),
+				EndTime:   time.Now(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		notifier.Queries.Flush()
+
+		mu.Lock()
+		queries := sent["queries-stats"].Queries
+		mu.Unlock()
+		Expect(queries).To(HaveLen(3))
+		Expect(queries).To(ContainElement(apmKey{
+			Method: "__other__",
+			Route:  "__other__",
+			Query:  "__other__",
+			Count:  1,
+		}))
+		Expect(notifier.Stats().QueriesFolded).To(BeNumerically("==", 1))
+	})
+
+	It("keeps time of folded queries", func() {
+		now := time.Now().UTC().Truncate(time.Minute)
+		for i, query := range []string{"SELECT * FROM a", "SELECT * FROM b", "SELECT * FROM c"} {
+			start := now.Add(-time.Duration(i) * time.Hour)
+			err := notifier.Queries.Notify(context.TODO(), &QueryInfo{
+				Query:     query,
+				StartTime: start,
+				EndTime:   start,
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		notifier.Queries.mu.Lock()
+		_, ok := notifier.Queries.m[queryKey{
+			Method: otherKey,
+			Route:  otherKey,
+			Query:  otherKey,
+			Func:   otherKey,
+			File:   otherKey,
+			Time:   now.Add(-2 * time.Hour),
+		}]
+		notifier.Queries.mu.Unlock()
+		Expect(ok).To(BeTrue())
+	})
+
+	It("folds queues over the limit into __other__", func() {
+		for _, queue := range []string{"a", "b", "c"} {
+			c, metric := NewQueueMetric(context.TODO(), queue)
+			err := notifier.Queues.Notify(c, metric)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		notifier.Queues.flush()
+
+		mu.Lock()
+		queues := sent["queues-stats"].Queues
+		mu.Unlock()
+		var names []string
+		for _, q := range queues {
+			names = append(names, q.Queue)
+		}
+		Expect(names).To(ConsistOf("a", "b", "__other__"))
+		Expect(notifier.Stats().QueuesFolded).To(BeNumerically("==", 1))
+	})
+})
@@ -0,0 +1,141 @@
+package gobrake
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// logPrefixRe matches date and time printed by log.Logger with the
+// log.LstdFlags and log.Lmicroseconds flags.
+var logPrefixRe = regexp.MustCompile(
+	`^(?:\d{4}/\d{2}/\d{2} )?(?:\d{2}:\d{2}:\d{2}(?:\.\d+)? )?`)
+
+type LogWriterOptions struct {
+	// Writer that receives everything written to the LogWriter.
+	// Default is os.Stderr.
+	Output io.Writer
+
+	// Only lines matching the regular expression are reported. Go panic
+	// traces are always reported. Default is nil, which reports only panic
+	// traces.
+	Match *regexp.Regexp
+
+	// Severity of reported notices. Default is error.
+	Severity string
+}
+
+// LogWriter is an io.Writer that can be used as output of log.Logger, e.g.
+// with log.SetOutput or in http.Server.ErrorLog. It copies output to another
+// writer and reports Go panic traces to Airbrake as panics with backtraces.
+// Other logged lines are reported only when they match Match.
+//
+// Every Write is treated as a single log entry, which is how log.Logger
+// writes. Incomplete lines are buffered until the next Write.
+type LogWriter struct {
+	notifier *Notifier
+	opt      *LogWriterOptions
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+var _ io.Writer = (*LogWriter)(nil)
+
+func NewLogWriter(notifier *Notifier, opt *LogWriterOptions) *LogWriter {
+	if opt == nil {
+		opt = new(LogWriterOptions)
+	}
+	if opt.Output == nil {
+		opt.Output = os.Stderr
+	}
+	if opt.Severity == "" {
+		opt.Severity = "error"
+	}
+	return &LogWriter{
+		notifier: notifier,
+		opt:      opt,
+	}
+}
+
+func (w *LogWriter) Write(p []byte) (int, error) {
+	n, err := w.opt.Output.Write(p)
+
+	w.mu.Lock()
+	w.buf = append(w.buf, p...)
+	var entry string
+	if ind := bytes.LastIndexByte(w.buf, '\n'); ind != -1 {
+		entry = string(w.buf[:ind+1])
+		w.buf = append(w.buf[:0], w.buf[ind+1:]...)
+	}
+	w.mu.Unlock()
+
+	if entry != "" {
+		w.report(entry)
+	}
+
+	return n, err
+}
+
+func (w *LogWriter) report(entry string) {
+	if component, errors, ok := parsePanicTrace(entry); ok {
+		// The backtrace and the component come from the trace of the
+		// panicking goroutine, not from the stack of this call.
+		notice := w.notifier.Notice(errors[0].Message, nil, -1)
+		notice.Errors = errors
+		notice.Context["component"] = component
+		notice.Context["severity"] = "critical"
+		w.notifier.Notify(notice, nil)
+		return
+	}
+
+	if w.opt.Match == nil {
+		return
+	}
+
+	for _, line := range strings.Split(entry, "\n") {
+		line = logPrefixRe.ReplaceAllString(line, "")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Don't report our own logs to avoid feedback loops.
+		if prefix := logger.Prefix(); prefix != "" && strings.HasPrefix(line, prefix) {
+			continue
+		}
+		if !w.opt.Match.MatchString(line) {
+			continue
+		}
+
+		notice := w.notifier.Notice(line, nil, logCallerDepth())
+		notice.Context["severity"] = w.opt.Severity
+		w.notifier.Notify(notice, nil)
+	}
+}
+
+// logCallerDepth returns the depth of the first frame outside of the log
+// package and LogWriter relative to the caller of logCallerDepth.
+func logCallerDepth() int {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var depth int
+	for {
+		f, ok := frames.Next()
+		if !ok {
+			return 0
+		}
+		pkg, fn := splitPackageFuncName(f.Function)
+		isWriter := pkg == "github.com/airbrake/gobrake/v4" &&
+			strings.HasPrefix(fn, "(*LogWriter)")
+		if !isWriter && pkg != "log" {
+			return depth
+		}
+		depth++
+	}
+}
@@ -0,0 +1,185 @@
+package gobrake_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+const httpPanicTrace = `2020/01/02 15:04:05 http: panic serving 127.0.0.1:45612: assignment to entry in nil map
+goroutine 10 [running]:
+net/http.(*conn).serve.func1()
+	/usr/local/go/src/net/http/server.go:1939 +0xbb
+panic({0x992d90?, 0xa12450?})
+	/usr/local/go/src/runtime/panic.go:859 +0x125
+main.handler({0x634aa9?, 0x10?}, 0x99c458?)
+	/app/main.go:6 +0x28
+net/http.HandlerFunc.ServeHTTP(0x482bd9?, {0x9d1308?, 0x2149715f6000?}, 0x2149715e7af0?)
+	/usr/local/go/src/net/http/server.go:2338 +0x29
+created by net/http.(*Server).Serve in goroutine 8
+	/usr/local/go/src/net/http/server.go:3581 +0x4fd
+`
+
+const nestedPanicTrace = `panic: first [recovered]
+	panic: second
+
+goroutine 1 [running]:
+main.main.func1()
+	/app/main.go:10 +0x45
+panic({0x4a1b20?, 0x4dd2c8?})
+	/usr/local/go/src/runtime/panic.go:770 +0x132
+main.main()
+	/app/main.go:13 +0x49
+exit status 2
+`
+
+var _ = Describe("LogWriter", func() {
+	var notifier *gobrake.Notifier
+	var out *bytes.Buffer
+	var w *gobrake.LogWriter
+
+	var mu sync.Mutex
+	var sentNotices []*gobrake.Notice
+
+	BeforeEach(func() {
+		sentNotices = nil
+
+		handler := func(rw http.ResponseWriter, req *http.Request) {
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotices = append(sentNotices, notice)
+			mu.Unlock()
+
+			rw.WriteHeader(http.StatusCreated)
+			_, _ = rw.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+		configServer := newConfigServer()
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: configServer.URL,
+			DisableCodeHunks: true,
+		})
+
+		out = new(bytes.Buffer)
+		w = gobrake.NewLogWriter(notifier, &gobrake.LogWriterOptions{
+			Output: out,
+			Match:  regexp.MustCompile("(?i)error|panic"),
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("reports matching lines logged with log.Logger", func() {
+		l := log.New(w, "", log.LstdFlags)
+		l.Printf("starting server")
+		l.Printf("http: TLS handshake error from 1.2.3.4:5678: EOF")
+		notifier.Flush()
+
+		Expect(out.String()).To(ContainSubstring("starting server"))
+		Expect(sentNotices).To(HaveLen(1))
+
+		notice := sentNotices[0]
+		e := notice.Errors[0]
+		Expect(e.Message).To(Equal("http: TLS handshake error from 1.2.3.4:5678: EOF"))
+		Expect(e.Backtrace[0].File).To(HaveSuffix("log_writer_test.go"))
+		Expect(notice.Context["severity"]).To(Equal("error"))
+	})
+
+	It("reports only panic traces without Match", func() {
+		w = gobrake.NewLogWriter(notifier, &gobrake.LogWriterOptions{
+			Output: out,
+		})
+
+		l := log.New(w, "", log.LstdFlags)
+		l.Printf("http: TLS handshake error from 1.2.3.4:5678: EOF")
+		notifier.Flush()
+		Expect(out.String()).To(ContainSubstring("TLS handshake error"))
+		Expect(sentNotices).To(BeEmpty())
+
+		_, _ = w.Write([]byte(httpPanicTrace))
+		notifier.Flush()
+		Expect(sentNotices).To(HaveLen(1))
+		Expect(sentNotices[0].Errors[0].Type).To(Equal("panic"))
+	})
+
+	It("buffers incomplete lines", func() {
+		_, _ = w.Write([]byte("error: conn"))
+		notifier.Flush()
+		Expect(sentNotices).To(BeEmpty())
+
+		_, _ = w.Write([]byte("ection reset\n"))
+		notifier.Flush()
+		Expect(sentNotices).To(HaveLen(1))
+		Expect(sentNotices[0].Errors[0].Message).To(Equal("error: connection reset"))
+	})
+
+	It("parses http.Server panic traces", func() {
+		// Frames in GOROOT of the toolchain are rewritten to /GOROOT.
+		trace := strings.Replace(httpPanicTrace, "/usr/local/go/", runtime.GOROOT()+"/", -1)
+		_, _ = w.Write([]byte(trace))
+		notifier.Flush()
+
+		Expect(sentNotices).To(HaveLen(1))
+		notice := sentNotices[0]
+		Expect(notice.Context["severity"]).To(Equal("critical"))
+		Expect(notice.Context["component"]).To(Equal("main"))
+		Expect(notice.Errors).To(HaveLen(1))
+
+		e := notice.Errors[0]
+		Expect(e.Type).To(Equal("panic"))
+		Expect(e.Message).To(Equal("assignment to entry in nil map"))
+		Expect(e.Backtrace).To(Equal([]gobrake.StackFrame{{
+			File: "/app/main.go",
+			Line: 6,
+			Func: "handler",
+			Kind: gobrake.FrameApp,
+		}, {
+			File: "/GOROOT/src/net/http/server.go",
+			Line: 2338,
+			Func: "HandlerFunc.ServeHTTP",
+			Kind: gobrake.FrameStdlib,
+		}, {
+			File: "/GOROOT/src/net/http/server.go",
+			Line: 3581,
+			Func: "(*Server).Serve",
+			Kind: gobrake.FrameStdlib,
+		}}))
+	})
+
+	It("parses nested runtime panics", func() {
+		_, _ = w.Write([]byte(nestedPanicTrace))
+		notifier.Flush()
+
+		Expect(sentNotices).To(HaveLen(1))
+		errors := sentNotices[0].Errors
+		Expect(errors).To(HaveLen(2))
+		Expect(errors[0].Message).To(Equal("second"))
+		Expect(errors[0].Backtrace).To(HaveLen(1))
+		Expect(errors[0].Backtrace[0].Line).To(Equal(13))
+		Expect(errors[1].Message).To(Equal("first"))
+	})
+})
@@ -13,6 +13,7 @@ type ctxKey string
 
 const metricCtxKey ctxKey = "ab_metric"
 const spanCtxKey ctxKey = "ab_span"
+const requestCtxKey ctxKey = "ab_request"
 
 type Metric interface {
 	Start(c context.Context, name string) (context.Context, Span)
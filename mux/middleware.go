@@ -0,0 +1,55 @@
+// Package mux provides a gorilla/mux middleware that reports panics to
+// Airbrake and collects route stats.
+package mux
+
+import (
+	"net/http"
+
+	"github.com/airbrake/gobrake/v4"
+	httpbrake "github.com/airbrake/gobrake/v4/http"
+
+	"github.com/gorilla/mux"
+)
+
+// NewMiddleware returns a middleware for mux.Router.Use that reports route
+// stats using path templates, e.g. /users/{id}, and reports panics as
+// critical notices. The route metric and the request are stored in the
+// request context. Panics are answered with 500 Internal Server Error.
+func NewMiddleware(notifier *gobrake.Notifier) mux.MiddlewareFunc {
+	return httpbrake.New(notifier, &httpbrake.Options{
+		RouteName: RouteName,
+	}).Handler
+}
+
+// RouteName returns the path template of the matched route. Requests that
+// don't match any route are reported as UNKNOWN.
+func RouteName(req *http.Request) string {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return "UNKNOWN"
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return "UNKNOWN"
+	}
+	return tpl
+}
+
+// RouterRouteName returns a route name resolver that matches requests
+// against the router and uses path templates of its routes. It is meant for
+// middlewares that run outside of the router, e.g. in Negroni, where
+// mux.CurrentRoute is not available. Requests that don't match any route are
+// reported as UNKNOWN.
+func RouterRouteName(router *mux.Router) func(*http.Request) string {
+	return func(req *http.Request) string {
+		var match mux.RouteMatch
+		if !router.Match(req, &match) || match.Route == nil {
+			return "UNKNOWN"
+		}
+		tpl, err := match.Route.GetPathTemplate()
+		if err != nil {
+			return "UNKNOWN"
+		}
+		return tpl
+	}
+}
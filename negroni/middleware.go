@@ -8,35 +8,89 @@ import (
 	"github.com/urfave/negroni"
 )
 
+type Options struct {
+	// RouteName returns the route name of the request for route stats. It is
+	// called after the request is handled. Default is NormalizedPath. Use
+	// RouterRouteName of github.com/airbrake/gobrake/v4/mux for gorilla/mux
+	// routers.
+	RouteName func(*http.Request) string
+}
+
+// NormalizedPath is a route name resolver that replaces IDs in the URL path
+// with placeholders using gobrake.NormalizePath.
+func NormalizedPath(req *http.Request) string {
+	return gobrake.NormalizePath(req.URL.Path)
+}
+
 // NewMiddleware implements a middleware that can be used in Negroni
 func NewMiddleware(n *gobrake.Notifier) negroni.Handler {
+	return NewMiddlewareWithOptions(n, nil)
+}
+
+// NewMiddlewareWithOptions returns a middleware that reports route stats of
+// every request and reports recovered panics as critical notices. The route
+// metric and the request are stored in the request context passed to next.
+// Panics are answered with 500 Internal Server Error unless the response was
+// already started.
+func NewMiddlewareWithOptions(n *gobrake.Notifier, opt *Options) negroni.Handler {
 	if n == nil {
 		return negroni.HandlerFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) { next(w, r) })
 	}
+	if opt == nil {
+		opt = new(Options)
+	}
+	if opt.RouteName == nil {
+		opt.RouteName = NormalizedPath
+	}
 	return negroni.HandlerFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		route := r.URL.Path
-		ctx := r.Context()
-		ctx, routeMetric := gobrake.NewRouteMetric(ctx, r.Method, route)
-		arw := newAirbrakeResponseWriter(w)
-		next(arw, r)
-		routeMetric.StatusCode = arw.statusCode
-		err := n.Routes.Notify(ctx, routeMetric)
-		if err != nil {
-			log.Println("[airbrake/error]: ", err)
+		// The route name is known only after the request is handled, so
+		// queries that run during the request see the normalized path.
+		ctx, routeMetric := gobrake.NewRouteMetric(
+			r.Context(), r.Method, gobrake.NormalizePath(r.URL.Path))
+		ctx = gobrake.ContextWithRequest(ctx, r)
+		r = r.WithContext(ctx)
+
+		// Negroni passes its own ResponseWriter, which already records the
+		// status and implements the optional interfaces of the original one.
+		rw, ok := w.(negroni.ResponseWriter)
+		if !ok {
+			rw = negroni.NewResponseWriter(w)
 		}
-	})
-}
 
-type airbrakeResponseWriter struct {
-	http.ResponseWriter
-	statusCode int
-}
+		defer func() {
+			v := recover()
+			if v != nil && v != http.ErrAbortHandler {
+				notifyPanic(n, v, r, opt)
+				if !rw.Written() {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError),
+						http.StatusInternalServerError)
+				}
+			}
 
-func newAirbrakeResponseWriter(w http.ResponseWriter) *airbrakeResponseWriter {
-	return &airbrakeResponseWriter{w, http.StatusOK}
+			routeMetric.Route = opt.RouteName(r)
+			routeMetric.StatusCode = rw.Status()
+			if routeMetric.StatusCode == 0 {
+				routeMetric.StatusCode = http.StatusOK
+			}
+			routeMetric.ContentType = rw.Header().Get("Content-Type")
+			err := n.Routes.Notify(ctx, routeMetric)
+			if err != nil {
+				log.Println("[airbrake/error]: ", err)
+			}
+
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+		}()
+
+		next(rw, r)
+	})
 }
 
-func (arw *airbrakeResponseWriter) WriteHeader(code int) {
-	arw.statusCode = code
-	arw.ResponseWriter.WriteHeader(code)
+func notifyPanic(n *gobrake.Notifier, v interface{}, r *http.Request, opt *Options) {
+	// Skip this function, the deferred function and runtime.gopanic.
+	notice := n.Notice(v, r, 3)
+	notice.Context["severity"] = "critical"
+	notice.Context["route"] = opt.RouteName(r)
+	n.Notify(notice, r)
 }
@@ -0,0 +1,175 @@
+package negroni_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/urfave/negroni"
+
+	"github.com/airbrake/gobrake/v4"
+	ng "github.com/airbrake/gobrake/v4/negroni"
+)
+
+func TestNegroni(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "negroni")
+}
+
+var _ = Describe("NewMiddlewareWithOptions", func() {
+	var notifier *gobrake.Notifier
+	var opt *ng.Options
+
+	var mu sync.Mutex
+	var sentNotice *gobrake.Notice
+	var metric *gobrake.RouteMetric
+
+	BeforeEach(func() {
+		sentNotice = nil
+		metric = nil
+		opt = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			notice := new(gobrake.Notice)
+			err = json.Unmarshal(b, notice)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			sentNotice = notice
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		notifier.Routes.AddFilter(func(m *gobrake.RouteMetric) *gobrake.RouteMetric {
+			metric = m
+			return nil
+		})
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	serve := func(h http.Handler) *httptest.ResponseRecorder {
+		n := negroni.New()
+		n.Use(ng.NewMiddlewareWithOptions(notifier, opt))
+		n.UseHandler(h)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/users/123", nil)
+		n.ServeHTTP(rec, req)
+		notifier.Flush()
+		return rec
+	}
+
+	It("reports route stats", func() {
+		var reqMetric *gobrake.RouteMetric
+		var reqRequest *http.Request
+		rec := serve(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			reqMetric = gobrake.ContextRouteMetric(req.Context())
+			reqRequest = gobrake.ContextRequest(req.Context())
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		Expect(metric).NotTo(BeNil())
+		Expect(reqMetric).To(BeIdenticalTo(metric))
+		Expect(reqRequest).NotTo(BeNil())
+		Expect(metric.Method).To(Equal("GET"))
+		Expect(metric.Route).To(Equal("/users/:id"))
+		Expect(metric.StatusCode).To(Equal(http.StatusCreated))
+		Expect(metric.ContentType).To(Equal("application/json"))
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+	})
+
+	It("passes negroni.ResponseWriter to next", func() {
+		var ok, hijacker bool
+		serve(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, ok = w.(negroni.ResponseWriter)
+			_, hijacker = w.(http.Hijacker)
+		}))
+
+		Expect(ok).To(BeTrue())
+		Expect(hijacker).To(BeTrue())
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("normalizes paths by default", func() {
+		serve(http.NotFoundHandler())
+		Expect(metric.Route).To(Equal("/users/:id"))
+	})
+
+	Context("with NormalizedPath", func() {
+		BeforeEach(func() {
+			opt = &ng.Options{RouteName: ng.NormalizedPath}
+		})
+
+		It("uses normalized path", func() {
+			serve(http.NotFoundHandler())
+			Expect(metric.Route).To(Equal("/users/:id"))
+		})
+	})
+
+	Context("with RouteName", func() {
+		BeforeEach(func() {
+			opt = &ng.Options{RouteName: func(req *http.Request) string {
+				return req.URL.Path
+			}}
+		})
+
+		It("uses route name", func() {
+			serve(http.NotFoundHandler())
+			Expect(metric.Route).To(Equal("/users/123"))
+		})
+	})
+
+	It("reports panics", func() {
+		rec := serve(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			panic("boom")
+		}))
+
+		Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		Expect(metric.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(sentNotice).NotTo(BeNil())
+		Expect(sentNotice.Errors[0].Message).To(Equal("boom"))
+		Expect(sentNotice.Errors[0].Backtrace[0].File).To(HaveSuffix("middleware_test.go"))
+		Expect(sentNotice.Context["severity"]).To(Equal("critical"))
+		Expect(sentNotice.Context["route"]).To(Equal("/users/:id"))
+	})
+
+	It("reports written status on panics after the response was started", func() {
+		rec := serve(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			panic("boom")
+		}))
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(metric.StatusCode).To(Equal(http.StatusOK))
+	})
+})
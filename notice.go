@@ -1,12 +1,12 @@
 package gobrake
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -40,6 +40,8 @@ func getDefaultContext() map[string]interface{} {
 		if s := gopath(); s != "" {
 			defaultContext["gopath"] = s
 		}
+
+		addBuildInfoContext(defaultContext)
 	})
 	return defaultContext
 }
@@ -69,6 +71,10 @@ type StackFrame struct {
 	Line int            `json:"line"`
 	Func string         `json:"function"`
 	Code map[int]string `json:"code,omitempty"`
+	Kind string         `json:"kind,omitempty"` // FrameApp, FrameLibrary or FrameStdlib
+
+	// Inlined is true when the compiler inlined the function into its caller.
+	Inlined bool `json:"inlined,omitempty"`
 }
 
 type Notice struct {
@@ -80,6 +86,8 @@ type Notice struct {
 	Env     map[string]interface{} `json:"environment"`
 	Session map[string]interface{} `json:"session"`
 	Params  map[string]interface{} `json:"params"`
+
+	err interface{} // error the notice was created from
 }
 
 func (n *Notice) String() string {
@@ -91,12 +99,16 @@ func (n *Notice) String() string {
 }
 
 func (n *Notice) SetRequest(req *http.Request) {
+	n.setRequest(req, defaultNoticeOptions.ClientIPResolver)
+}
+
+func (n *Notice) setRequest(req *http.Request, clientIP func(*http.Request) string) {
 	n.Context["url"] = req.URL.String()
 	n.Context["httpMethod"] = req.Method
 	if ua := req.Header.Get("User-Agent"); ua != "" {
 		n.Context["userAgent"] = ua
 	}
-	n.Context["userAddr"] = remoteAddr(req)
+	n.Context["userAddr"] = clientIP(req)
 
 	for k, v := range req.Header {
 		if len(v) == 1 {
@@ -107,21 +119,33 @@ func (n *Notice) SetRequest(req *http.Request) {
 	}
 }
 
-func remoteAddr(req *http.Request) string {
-	if s := req.Header.Get("X-Forwarded-For"); s != "" {
-		parts := strings.Split(s, ",")
-		return parts[0]
-	}
+// ContextWithRequest returns a copy of c that carries req. Integrations use
+// it to attach the request to notices that are created deeper in the stack.
+func ContextWithRequest(c context.Context, req *http.Request) context.Context {
+	return context.WithValue(c, requestCtxKey, req)
+}
 
-	if s := req.Header.Get("X-Real-Ip"); s != "" {
-		return s
+// ContextRequest returns the request stored in c by ContextWithRequest.
+func ContextRequest(c context.Context) *http.Request {
+	if c == nil {
+		return nil
 	}
-
-	parts := strings.Split(req.RemoteAddr, ":")
-	return parts[0]
+	req, _ := c.Value(requestCtxKey).(*http.Request)
+	return req
 }
 
+// defaultNoticeOptions are used by NewNotice.
+var defaultNoticeOptions = func() *NotifierOptions {
+	opt := new(NotifierOptions)
+	opt.init()
+	return opt
+}()
+
 func NewNotice(e interface{}, req *http.Request, depth int) *Notice {
+	return newNotice(e, req, depth+1, defaultNoticeOptions)
+}
+
+func newNotice(e interface{}, req *http.Request, depth int, opt *NotifierOptions) *Notice {
 	notice, ok := e.(*Notice)
 	if ok {
 		return notice
@@ -137,6 +161,8 @@ func NewNotice(e interface{}, req *http.Request, depth int) *Notice {
 		Env:     make(map[string]interface{}),
 		Session: make(map[string]interface{}),
 		Params:  make(map[string]interface{}),
+
+		err: e,
 	}
 
 	for k, v := range getDefaultContext() {
@@ -144,13 +170,16 @@ func NewNotice(e interface{}, req *http.Request, depth int) *Notice {
 	}
 
 	if depth != -1 {
-		packageName, backtrace := getBacktrace(e, depth+2)
+		packageName, backtrace := getBacktrace(e, depth+2, opt)
 		notice.Errors[0].Backtrace = backtrace
 		notice.Context["component"] = packageName
+		if id := goroutineID(); id != 0 {
+			notice.Context["goroutineId"] = id
+		}
 	}
 
 	if req != nil {
-		notice.SetRequest(req)
+		notice.setRequest(req, opt.ClientIPResolver)
 	}
 
 	return notice
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"regexp"
@@ -17,7 +18,7 @@ import (
 )
 
 const notifierName = "gobrake"
-const notifierVersion = "4.2.0"
+const notifierVersion = "4.3.0"
 const userAgent = notifierName + "/" + notifierVersion
 
 const waitTimeout = 5 * time.Second
@@ -31,6 +32,7 @@ var (
 	errClosed             = errors.New("gobrake: notifier is closed")
 	errQueueFull          = errors.New("gobrake: queue is full (error is dropped)")
 	errUnauthorized       = errors.New("gobrake: unauthorized: invalid project id or key")
+	errProjectNotFound    = errors.New("gobrake: project not found: check host and project id")
 	errAccountRateLimited = errors.New("gobrake: account is rate limited")
 	errIPRateLimited      = errors.New("gobrake: IP is rate limited")
 	errNoticeTooBig       = errors.New("gobrake: notice exceeds 64KB max size limit")
@@ -77,9 +79,15 @@ type NotifierOptions struct {
 	// Environment such as production or development.
 	Environment string
 
-	// Git revision. Default is SOURCE_VERSION on Heroku.
+	// Git revision. Default is SOURCE_VERSION on Heroku, then the revision
+	// of the .git directory and then the VCS revision embedded by the go
+	// command.
 	Revision string
 
+	// Reports versions of the modules the binary is built with in
+	// context.dependencies.
+	ReportDependencies bool
+
 	// List of keys containing sensitive information that must be filtered out.
 	// Default is password, secret.
 	KeysBlocklist []interface{}
@@ -88,17 +96,102 @@ type NotifierOptions struct {
 	// be removed in a future release.
 	KeysBlacklist []interface{}
 
+	// Enables redaction of secrets and personal data, such as JWTs, bearer
+	// tokens, credit card numbers and emails, found in error messages and
+	// notice values.
+	RedactValues bool
+
+	// List of additional values that must be redacted from error messages
+	// and notice values. Setting it implies RedactValues.
+	ValuesBlocklist []interface{}
+
+	// List of error type names, e.g. "*net.OpError", that must be ignored.
+	IgnoreErrorTypes []string
+
+	// List of regular expressions matching messages of errors that must be
+	// ignored.
+	IgnoreMessages []*regexp.Regexp
+
+	// List of sentinel errors that must be ignored. Errors are matched with
+	// errors.Is.
+	IgnoreErrors []error
+
+	// List of HTTP status codes of errors that must be ignored. Errors report
+	// status code with the StatusCode() int method.
+	IgnoreStatusCodes []int
+
+	// List of environments where errors are not reported.
+	IgnoreEnvironments []string
+
 	// Disables code hunks.
 	DisableCodeHunks bool
 
+	// Source files used for code hunks when the binary runs without its
+	// source tree, e.g. a //go:embed of the module sources or a *zip.Reader.
+	// The file system must be rooted at the main module root; files are
+	// looked up by path relative to it, which also works with -trimpath
+	// builds. Files not found in SourceFS are read from the local file
+	// system.
+	SourceFS fs.FS
+
+	// Number of lines before and after the frame line in code hunks.
+	// Default is 2. A negative value means no context lines, so code hunks
+	// contain only the frame line.
+	CodeHunkLines int
+
+	// Maximum length of code hunk lines. Longer lines are truncated.
+	// Default is 512.
+	CodeHunkMaxLineLen int
+
+	// Maximum number of backtrace frames. Default is 32.
+	BacktraceDepth int
+
+	// Backtrace frames of functions matching one of the regular expressions
+	// are skipped, e.g. error helpers and logging adapters. Expressions are
+	// matched against the full function name, e.g.
+	// "github.com/pkg/errors.Wrap" or "example.com/log.(*Logger).Error".
+	SkipFrames []*regexp.Regexp
+
+	// Keeps only the first and the last frame of runs of library frames,
+	// so that deep framework stacks don't push application frames out of
+	// the backtrace.
+	CollapseLibraryFrames bool
+
+	// Rewrites of backtrace frame files, e.g. to strip the build directory.
+	// They are applied before built-in rules, which rewrite files of the
+	// main module to /PROJECT_ROOT, the module cache and vendor directories
+	// to /GOMODCACHE/<module>@<version>, GOROOT to /GOROOT and GOPATH/src
+	// to /GOPATH.
+	PathRewrites []PathRewrite
+
 	// Controls the error reporting feature.
 	DisableErrorNotifications bool
 
 	// Controls the error reporting feature.
 	DisableAPM bool
 
+	// Turns off normalization of queries with NormalizeQuery before their
+	// stats are aggregated. Queries must be normalized by the caller then.
+	DisableQueryNormalization bool
+
+	// Maximum number of distinct keys that route stats, route breakdowns,
+	// queries and queues each collect between flushes. Metrics with new keys
+	// over the limit are folded into a key with __other__ fields that keeps
+	// the time of the metric.
+	// Default is 10000.
+	APMMaxKeys int
+
 	// http.Client that is used to interact with Airbrake API.
 	HTTPClient *http.Client
+
+	// List of IP addresses and CIDR ranges of proxies that are trusted to set
+	// X-Forwarded-For, Forwarded and X-Real-Ip headers. Default is loopback
+	// and private networks. Set it to an empty list to trust no proxies.
+	TrustedProxies []string
+
+	// Function that returns the client IP address of the request.
+	// Overrides TrustedProxies.
+	ClientIPResolver func(*http.Request) string
 }
 
 func (opt *NotifierOptions) init() {
@@ -131,9 +224,33 @@ func (opt *NotifierOptions) init() {
 		}
 	}
 
+	if opt.BacktraceDepth <= 0 {
+		opt.BacktraceDepth = defaultBacktraceDepth
+	}
+
+	if opt.APMMaxKeys <= 0 {
+		opt.APMMaxKeys = defaultAPMMaxKeys
+	}
+
+	if opt.CodeHunkLines == 0 {
+		opt.CodeHunkLines = defaultCodeHunkLines
+	}
+
+	if opt.CodeHunkMaxLineLen <= 0 {
+		opt.CodeHunkMaxLineLen = defaultCodeHunkMaxLineLen
+	}
+
 	if opt.HTTPClient == nil {
 		opt.HTTPClient = defaultHTTPClient()
 	}
+
+	if opt.ClientIPResolver == nil {
+		if opt.TrustedProxies == nil {
+			opt.ClientIPResolver = defaultClientIP
+		} else {
+			opt.ClientIPResolver = newClientIPResolver(opt.TrustedProxies)
+		}
+	}
 }
 
 type routes struct {
@@ -186,6 +303,7 @@ func (rs *routes) Notify(c context.Context, metric *RouteMetric) error {
 type Notifier struct {
 	opt     *NotifierOptions
 	filters []filter
+	ignore  *ignoreRules
 
 	inFlight int32 // atomic
 	limit    chan struct{}
@@ -195,6 +313,8 @@ type Notifier struct {
 	Queries *queryStats
 	Queues  *queueStats
 
+	codeHunks *codeHunks
+
 	rateLimitReset uint32 // atomic
 	_closed        uint32 // atomic
 
@@ -215,18 +335,28 @@ func NewNotifierWithOptions(opt *NotifierOptions) *Notifier {
 		remoteConfig: newRemoteConfig(opt),
 	}
 
+	n.ignore = newIgnoreRules(opt)
+	if !n.ignore.empty() {
+		n.AddFilter(newIgnoreFilter(n.ignore))
+	}
 	n.AddFilter(httpUnsolicitedResponseFilter)
 	n.AddFilter(newNotifierFilter(n))
 	n.AddFilter(gitFilter)
+	n.AddFilter(buildInfoRevisionFilter)
 	if !opt.DisableCodeHunks {
-		n.AddFilter(codeHunksFilter)
+		n.codeHunks = newCodeHunks(opt)
+		n.AddFilter(newCodeHunksFilter(n.codeHunks))
 	}
-	n.AddFilter(gopathFilter)
+	n.AddFilter(newPathRewriteFilter(opt))
 
 	if len(opt.KeysBlocklist) > 0 {
 		n.AddFilter(NewBlocklistKeysFilter(opt.KeysBlocklist...))
 	}
 
+	if opt.RedactValues || len(opt.ValuesBlocklist) > 0 {
+		n.AddFilter(NewRedactValuesFilter(opt.ValuesBlocklist...))
+	}
+
 	n.remoteConfig.Poll()
 
 	return n
@@ -254,6 +384,10 @@ func (n *Notifier) Notify(e interface{}, req *http.Request) {
 		return
 	}
 
+	if n.ignore.ignored(e) {
+		return
+	}
+
 	notice := n.Notice(e, req, 1)
 	n.SendNoticeAsync(notice)
 }
@@ -261,7 +395,13 @@ func (n *Notifier) Notify(e interface{}, req *http.Request) {
 // Notice returns Aibrake notice created from error and request. depth
 // determines which call frame to use when constructing backtrace.
 func (n *Notifier) Notice(err interface{}, req *http.Request, depth int) *Notice {
-	return NewNotice(err, req, depth+1)
+	return newNotice(err, req, depth+1, n.opt)
+}
+
+// ClientIP returns the client IP address of req resolved with the
+// TrustedProxies and ClientIPResolver options.
+func (n *Notifier) ClientIP(req *http.Request) string {
+	return n.opt.ClientIPResolver(req)
 }
 
 type sendResponse struct {
@@ -403,6 +543,10 @@ func (n *Notifier) SendNoticeAsync(notice *Notice) {
 // with defer statement.
 func (n *Notifier) NotifyOnPanic() {
 	if v := recover(); v != nil {
+		if n.ignore.ignored(v) {
+			panic(v)
+		}
+
 		notice := n.Notice(v, nil, 2)
 		notice.Context["severity"] = "critical"
 		_, err := n.SendNotice(notice)
@@ -214,13 +214,13 @@ var _ = Describe("Notifier", func() {
 		Expect(e.Message).To(Equal("Test"))
 
 		frame := e.Backtrace[0]
-		Expect(frame.File).To(ContainSubstring("gobrake/internal/testpkg1/testhelper.go"))
+		Expect(frame.File).To(Equal("/PROJECT_ROOT/internal/testpkg1/testhelper.go"))
 		Expect(frame.Line).To(Equal(10))
 		Expect(frame.Func).To(Equal("Bar"))
 		Expect(frame.Code[10]).To(Equal(`	return errors.New("Test")`))
 
 		frame = e.Backtrace[1]
-		Expect(frame.File).To(ContainSubstring("gobrake/internal/testpkg1/testhelper.go"))
+		Expect(frame.File).To(Equal("/PROJECT_ROOT/internal/testpkg1/testhelper.go"))
 		Expect(frame.Line).To(Equal(6))
 		Expect(frame.Func).To(Equal("Foo"))
 		Expect(frame.Code[6]).To(Equal("\treturn Bar()"))
@@ -283,8 +283,9 @@ var _ = Describe("Notifier", func() {
 		Expect(err).To(BeNil())
 
 		req := &http.Request{
-			Method: "GET",
-			URL:    u,
+			Method:     "GET",
+			URL:        u,
+			RemoteAddr: "10.0.0.1:4711",
 			Header: http.Header{
 				"User-Agent": {"my_user_agent"},
 				"X-Real-Ip":  {"127.0.0.1"},
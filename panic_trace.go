@@ -0,0 +1,163 @@
+package gobrake
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	goroutineHeaderRe = regexp.MustCompile(`(?m)^goroutine \d+ \[[^\]]*\]:\s*$`)
+	panicMessageRe    = regexp.MustCompile(`\bpanic(?: serving \S+)?: `)
+)
+
+// parsePanicTrace parses text printed by the Go runtime or by
+// net/http.Server when a goroutine panics, e.g.
+//
+//	panic: oops [recovered]
+//		panic: oops again
+//
+//	goroutine 1 [running]:
+//	main.main()
+//		/app/main.go:8 +0x1d
+//
+// Errors are returned most recent panic first and the backtrace is attached
+// to the first error. The package is the component of the panic like
+// getBacktrace returns it.
+func parsePanicTrace(text string) (string, []Error, bool) {
+	loc := goroutineHeaderRe.FindStringIndex(text)
+	if loc == nil {
+		return "", nil, false
+	}
+
+	errors := parsePanicMessages(text[:loc[0]])
+	if len(errors) == 0 {
+		return "", nil, false
+	}
+	var pkg string
+	pkg, errors[0].Backtrace = parseGoroutineFrames(text[loc[1]:])
+	return pkg, errors, true
+}
+
+func parsePanicMessages(text string) []Error {
+	var errors []Error
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if loc := panicMessageRe.FindStringIndex(line); loc != nil {
+			msg := line[loc[1]:]
+			msg = strings.TrimSuffix(msg, " [recovered]")
+			errors = append([]Error{{
+				Type:    "panic",
+				Message: msg,
+			}}, errors...)
+			continue
+		}
+
+		if len(errors) > 0 {
+			// Continuation of a multi-line panic message.
+			errors[0].Message += "\n" + line
+		}
+	}
+	return errors
+}
+
+func parseGoroutineFrames(text string) (string, []StackFrame) {
+	lines := strings.Split(text, "\n")
+	var firstPkg, appPkg string
+	frames := make([]StackFrame, 0)
+	for i := 0; i < len(lines); i++ {
+		fnLine := strings.TrimSpace(lines[i])
+		if fnLine == "" {
+			if len(frames) > 0 {
+				// End of the goroutine.
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(fnLine, "goroutine ") {
+			break
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "\t") {
+			continue
+		}
+		i++
+
+		file, line := parseFrameLocation(strings.TrimSpace(lines[i]))
+		pkg, fn := splitPackageFuncName(trimFrameArgs(fnLine))
+		if (pkg == "" || pkg == "runtime") && fn == "panic" ||
+			stackFilter(pkg, fn, file, line) {
+			frames = frames[:0]
+			firstPkg, appPkg = "", ""
+			continue
+		}
+
+		if firstPkg == "" && pkg != "runtime" {
+			firstPkg = pkg
+		}
+		kind := frameKind(pkg, file)
+		if appPkg == "" && kind == FrameApp {
+			appPkg = pkg
+		}
+
+		frames = append(frames, StackFrame{
+			File: file,
+			Line: line,
+			Func: fn,
+			Kind: kind,
+		})
+	}
+
+	if appPkg != "" {
+		firstPkg = appPkg
+	}
+	return firstPkg, frames
+}
+
+// trimFrameArgs removes call arguments and "created by" prefix from
+// a function line of a goroutine trace.
+func trimFrameArgs(s string) string {
+	if strings.HasPrefix(s, "created by ") {
+		s = strings.TrimPrefix(s, "created by ")
+		if ind := strings.Index(s, " in goroutine "); ind != -1 {
+			s = s[:ind]
+		}
+		return s
+	}
+
+	if !strings.HasSuffix(s, ")") {
+		return s
+	}
+	depth := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		switch s[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+// parseFrameLocation parses "/path/file.go:123 +0x1d".
+func parseFrameLocation(s string) (string, int) {
+	if ind := strings.LastIndex(s, " +0x"); ind != -1 {
+		s = s[:ind]
+	}
+	ind := strings.LastIndexByte(s, ':')
+	if ind == -1 {
+		return s, 0
+	}
+	line, err := strconv.Atoi(s[ind+1:])
+	if err != nil {
+		return s, 0
+	}
+	return s[:ind], line
+}
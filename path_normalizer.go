@@ -0,0 +1,129 @@
+package gobrake
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PathPattern replaces URL path segments that match Regexp with Placeholder.
+type PathPattern struct {
+	Regexp      *regexp.Regexp
+	Placeholder string
+}
+
+var (
+	numericIDRe = regexp.MustCompile(`^[0-9]+$`)
+	uuidRe      = regexp.MustCompile(
+		`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	dateRe = regexp.MustCompile(
+		`^[0-9]{4}-[0-9]{2}-[0-9]{2}` +
+			`([T ][0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?(Z|[+-][0-9]{2}:?[0-9]{2})?)?$`)
+	hexRe   = regexp.MustCompile(`^[0-9a-fA-F]{8,}$`)
+	tokenRe = regexp.MustCompile(`^[A-Za-z0-9_\-+=]{16,}$`)
+	wordRe  = regexp.MustCompile(`[A-Z]?[a-z]+`)
+)
+
+// PathNormalizer replaces path segments that look like IDs with
+// placeholders to keep the number of distinct route names small.
+//
+// Segments are matched against the override patterns first and then against
+// the built-in ones:
+//   - numeric IDs, e.g. 123, become :id;
+//   - UUIDs become :uuid;
+//   - dates and RFC 3339 timestamps, e.g. 2024-01-31, become :date;
+//   - hex hashes of 8 or more characters with at least one digit become :hash;
+//   - base64-like tokens of 16 or more characters that mix digits, upper
+//     and lower case letters and are not mostly words, e.g. GetUser2Factor,
+//     become :token.
+type PathNormalizer struct {
+	overrides []PathPattern
+}
+
+var defaultPathNormalizer = NewPathNormalizer()
+
+// NewPathNormalizer returns a normalizer that checks overrides in order
+// before the built-in patterns.
+func NewPathNormalizer(overrides ...PathPattern) *PathNormalizer {
+	return &PathNormalizer{
+		overrides: overrides,
+	}
+}
+
+// NormalizePath normalizes the path using the built-in patterns,
+// e.g. /users/123/orders/0b9e3a4c-5f7d-4c1e-9a2b-3c4d5e6f7a8b becomes
+// /users/:id/orders/:uuid.
+func NormalizePath(path string) string {
+	return defaultPathNormalizer.Normalize(path)
+}
+
+// Normalize returns the path with matching segments replaced by placeholders.
+func (n *PathNormalizer) Normalize(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if s == "" {
+			continue
+		}
+		if placeholder, ok := n.placeholder(s); ok {
+			segments[i] = placeholder
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Filter normalizes the route of the metric. It can be passed to
+// Notifier.Routes.AddFilter.
+func (n *PathNormalizer) Filter(metric *RouteMetric) *RouteMetric {
+	metric.Route = n.Normalize(metric.Route)
+	return metric
+}
+
+func (n *PathNormalizer) placeholder(s string) (string, bool) {
+	for _, p := range n.overrides {
+		if p.Regexp.MatchString(s) {
+			return p.Placeholder, true
+		}
+	}
+
+	switch {
+	case numericIDRe.MatchString(s):
+		return ":id", true
+	case uuidRe.MatchString(s):
+		return ":uuid", true
+	case dateRe.MatchString(s):
+		return ":date", true
+	case hexRe.MatchString(s) && strings.ContainsAny(s, "0123456789"):
+		return ":hash", true
+	case tokenRe.MatchString(s) && isMixedToken(s):
+		return ":token", true
+	}
+	return "", false
+}
+
+// isMixedToken reports whether s contains a digit and both upper and lower
+// case letters, which tells random tokens apart from words and slugs, and
+// less than half of s is made of words of 4 or more letters, which tells
+// them apart from CamelCase names such as ListUsersV2.
+func isMixedToken(s string) bool {
+	var digit, upper, lower bool
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digit = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		}
+	}
+	if !digit || !upper || !lower {
+		return false
+	}
+
+	var words int
+	for _, w := range wordRe.FindAllString(s, -1) {
+		if len(w) >= 4 {
+			words += len(w)
+		}
+	}
+	return 2*words < len(s)
+}
@@ -0,0 +1,68 @@
+package gobrake_test
+
+import (
+	"context"
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+var _ = Describe("NormalizePath", func() {
+	It("replaces IDs with placeholders", func() {
+		var tests = []struct {
+			path string
+			want string
+		}{
+			{"/", "/"},
+			{"/users/me", "/users/me"},
+			{"/v2/users", "/v2/users"},
+			{"/users/123", "/users/:id"},
+			{"/users/123/", "/users/:id/"},
+			{"/orders/0b9e3a4c-5f7d-4c1e-9a2b-3c4d5e6f7a8b", "/orders/:uuid"},
+			{"/users/42/orders/0B9E3A4C-5F7D-4C1E-9A2B-3C4D5E6F7A8B", "/users/:id/orders/:uuid"},
+			{"/reports/2024-01-31", "/reports/:date"},
+			{"/reports/2024-01-31T10:20:30Z", "/reports/:date"},
+			{"/commits/4b825dc642cb6eb9a060e54bf8d69288fbee4904", "/commits/:hash"},
+			{"/blobs/deadbeef", "/blobs/deadbeef"},
+			{"/reset/dGhpcyBpcyBhIHRva2VuMTI", "/reset/:token"},
+			{"/keys/aZ3kQ9xP7mN2bV5c", "/keys/:token"},
+			{"/api/v1/ListAllUsersByGroupV2", "/api/v1/ListAllUsersByGroupV2"},
+			{"/pkg.Service/GetUser2FactorStatusRequest", "/pkg.Service/GetUser2FactorStatusRequest"},
+			{"/api/OAuth2AuthorizationCallback", "/api/OAuth2AuthorizationCallback"},
+			{"/posts/internationalization", "/posts/internationalization"},
+			{"/posts/my-first-post-of-2024", "/posts/my-first-post-of-2024"},
+		}
+
+		for _, test := range tests {
+			Expect(gobrake.NormalizePath(test.path)).To(Equal(test.want), test.path)
+		}
+	})
+})
+
+var _ = Describe("PathNormalizer", func() {
+	var normalizer *gobrake.PathNormalizer
+
+	BeforeEach(func() {
+		normalizer = gobrake.NewPathNormalizer(gobrake.PathPattern{
+			Regexp:      regexp.MustCompile(`^[a-z]+@[a-z.]+$`),
+			Placeholder: ":email",
+		}, gobrake.PathPattern{
+			Regexp:      regexp.MustCompile(`^v[0-9]+$`),
+			Placeholder: ":version",
+		})
+	})
+
+	It("applies overrides before built-in patterns", func() {
+		path := normalizer.Normalize("/v2/users/bob@example.com/orders/123")
+		Expect(path).To(Equal("/:version/users/:email/orders/:id"))
+	})
+
+	It("normalizes route metrics", func() {
+		_, metric := gobrake.NewRouteMetric(context.Background(), "GET", "/users/123")
+		metric = normalizer.Filter(metric)
+		Expect(metric.Route).To(Equal("/users/:id"))
+	})
+})
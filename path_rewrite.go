@@ -0,0 +1,197 @@
+package gobrake
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PathRewrite replaces Prefix of backtrace frame files with Replacement.
+// Prefix matches whole path elements, e.g. "/app" matches "/app/main.go",
+// but not "/application/main.go".
+type PathRewrite struct {
+	Prefix      string
+	Replacement string
+}
+
+// pathRewriter returns the rewritten file or false if the rule doesn't apply.
+type pathRewriter func(file string) (string, bool)
+
+func newPathRewriteFilter(opt *NotifierOptions) func(*Notice) *Notice {
+	var rules []pathRewriter
+	for _, r := range opt.PathRewrites {
+		rules = append(rules, prefixRewriter(r.Prefix, r.Replacement))
+	}
+	rules = append(rules, modCacheRewriter(), vendorRewriter())
+	rules = append(rules, projectRootRewriters()...)
+	rules = append(rules, gorootRewriter(), gopathRewriter())
+
+	return func(notice *Notice) *Notice {
+		for i := range notice.Errors {
+			backtrace := notice.Errors[i].Backtrace
+			for j := range backtrace {
+				frame := &backtrace[j]
+				for _, rule := range rules {
+					if file, ok := rule(frame.File); ok {
+						frame.File = file
+						break
+					}
+				}
+			}
+		}
+		return notice
+	}
+}
+
+func prefixRewriter(prefix, replacement string) pathRewriter {
+	prefix = strings.TrimSuffix(filepath.ToSlash(prefix), "/")
+	replacement = strings.TrimSuffix(replacement, "/")
+	return func(file string) (string, bool) {
+		if prefix == "" {
+			return "", false
+		}
+		rest, ok := trimPathPrefix(file, prefix)
+		if !ok {
+			return "", false
+		}
+		return replacement + rest, true
+	}
+}
+
+// trimPathPrefix removes the prefix if it matches whole path elements.
+func trimPathPrefix(file, prefix string) (string, bool) {
+	file = filepath.ToSlash(file)
+	if !strings.HasPrefix(file, prefix) {
+		return "", false
+	}
+	rest := file[len(prefix):]
+	if rest != "" && rest[0] != '/' {
+		return "", false
+	}
+	return rest, true
+}
+
+// modCacheRewriter rewrites files in the module cache, e.g.
+// /home/user/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go and
+// github.com/pkg/errors@v0.9.1/errors.go in -trimpath builds, to
+// /GOMODCACHE/github.com/pkg/errors@v0.9.1/errors.go.
+func modCacheRewriter() pathRewriter {
+	return func(file string) (string, bool) {
+		file = filepath.ToSlash(file)
+		if ind := strings.Index(file, "/pkg/mod/"); ind != -1 {
+			rest := file[ind+len("/pkg/mod/"):]
+			if isModulePath(rest) {
+				return "/GOMODCACHE/" + rest, true
+			}
+		}
+		if !strings.HasPrefix(file, "/") && isModulePath(file) {
+			return "/GOMODCACHE/" + file, true
+		}
+		return "", false
+	}
+}
+
+// isModulePath reports whether the path starts with <module>@<version>/.
+func isModulePath(path string) bool {
+	ind := strings.Index(path, "@")
+	if ind <= 0 {
+		return false
+	}
+	mod := path[:ind]
+	version := path[ind+1:]
+	first := mod
+	if i := strings.IndexByte(first, '/'); i != -1 {
+		first = first[:i]
+	}
+	return strings.Contains(first, ".") &&
+		strings.HasPrefix(version, "v") &&
+		strings.Contains(version, "/")
+}
+
+// vendorRewriter rewrites files in vendor directories to the module cache
+// layout using module versions from the build info.
+func vendorRewriter() pathRewriter {
+	deps := getBuildDependencies()
+	return func(file string) (string, bool) {
+		file = filepath.ToSlash(file)
+		ind := strings.LastIndex(file, "/vendor/")
+		if ind == -1 {
+			return "", false
+		}
+		rest := file[ind+len("/vendor/"):]
+
+		var mod string
+		for path := range deps {
+			if strings.HasPrefix(rest, path+"/") && len(path) > len(mod) {
+				mod = path
+			}
+		}
+		if mod == "" {
+			return "", false
+		}
+
+		version, _ := deps[mod].(string)
+		return "/GOMODCACHE/" + mod + "@" + version + rest[len(mod):], true
+	}
+}
+
+// projectRootRewriters rewrite files of the main module to /PROJECT_ROOT,
+// which Airbrake resolves to the repository. The main module root is the
+// nearest directory with go.mod, falling back to the working directory.
+// In -trimpath builds files of the main module start with its path.
+func projectRootRewriters() []pathRewriter {
+	var rules []pathRewriter
+	if bi := getBuildInfo(); bi != nil && bi.Main.Path != "" {
+		rules = append(rules, prefixRewriter(bi.Main.Path, "/PROJECT_ROOT"))
+	}
+	if wd, _ := getDefaultContext()["rootDirectory"].(string); wd != "" {
+		root := wd
+		if dir, ok := findModuleRoot(wd); ok {
+			root = dir
+		}
+		rules = append(rules, prefixRewriter(root, "/PROJECT_ROOT"))
+	}
+	return rules
+}
+
+func findModuleRoot(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil || !exists(dir) {
+		return "", false
+	}
+
+	for i := 0; i < 10; i++ {
+		if exists(filepath.Join(dir, "go.mod")) {
+			return dir, true
+		}
+
+		if dir == "." || dir == "/" {
+			return "", false
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	return "", false
+}
+
+// gorootRewriter rewrites files in GOROOT of the machine that built the
+// binary to /GOROOT.
+func gorootRewriter() pathRewriter {
+	return prefixRewriter(buildGOROOT(), "/GOROOT")
+}
+
+func gopathRewriter() pathRewriter {
+	var rules []pathRewriter
+	s, _ := getDefaultContext()["gopath"].(string)
+	for _, dir := range filepath.SplitList(s) {
+		rules = append(rules, prefixRewriter(filepath.Join(dir, "src"), "/GOPATH"))
+	}
+	return func(file string) (string, bool) {
+		for _, rule := range rules {
+			if file, ok := rule(file); ok {
+				return file, true
+			}
+		}
+		return "", false
+	}
+}
@@ -0,0 +1,94 @@
+package gobrake
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("newPathRewriteFilter", func() {
+	rewrite := func(opt *NotifierOptions, files ...string) []string {
+		notice := &Notice{Errors: []Error{{}}}
+		for _, file := range files {
+			notice.Errors[0].Backtrace = append(notice.Errors[0].Backtrace,
+				StackFrame{File: file})
+		}
+
+		notice = newPathRewriteFilter(opt)(notice)
+
+		var rewritten []string
+		for _, frame := range notice.Errors[0].Backtrace {
+			rewritten = append(rewritten, frame.File)
+		}
+		return rewritten
+	}
+
+	It("rewrites the module cache", func() {
+		Expect(rewrite(&NotifierOptions{},
+			"/home/user/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go",
+			"github.com/pkg/errors@v0.9.1/errors.go",
+		)).To(Equal([]string{
+			"/GOMODCACHE/github.com/pkg/errors@v0.9.1/errors.go",
+			"/GOMODCACHE/github.com/pkg/errors@v0.9.1/errors.go",
+		}))
+	})
+
+	It("rewrites the project root", func() {
+		wd, _ := os.Getwd()
+		Expect(rewrite(&NotifierOptions{},
+			filepath.Join(wd, "internal", "testpkg1", "testhelper.go"),
+			"github.com/airbrake/gobrake/v4/notifier.go",
+		)).To(Equal([]string{
+			"/PROJECT_ROOT/internal/testpkg1/testhelper.go",
+			"/PROJECT_ROOT/notifier.go",
+		}))
+	})
+
+	It("rewrites vendor directories using module versions", func() {
+		wd, _ := os.Getwd()
+		Expect(rewrite(&NotifierOptions{},
+			filepath.Join(wd, "vendor", "github.com", "onsi", "gomega", "matchers.go"),
+			filepath.Join(wd, "vendor", "example.com", "unknown", "file.go"),
+		)).To(Equal([]string{
+			"/GOMODCACHE/github.com/onsi/gomega@" +
+				getBuildDependencies()["github.com/onsi/gomega"].(string) + "/matchers.go",
+			"/PROJECT_ROOT/vendor/example.com/unknown/file.go",
+		}))
+	})
+
+	It("rewrites GOROOT", func() {
+		pc := reflect.ValueOf(runtime.Gosched).Pointer()
+		file, _ := runtime.FuncForPC(pc).FileLine(pc)
+		goroot := file[:strings.LastIndex(file, "/src/runtime/")]
+
+		Expect(rewrite(&NotifierOptions{},
+			goroot+"/src/net/http/server.go",
+		)).To(Equal([]string{
+			"/GOROOT/src/net/http/server.go",
+		}))
+	})
+
+	It("applies custom rewrites first", func() {
+		wd, _ := os.Getwd()
+		opt := &NotifierOptions{
+			PathRewrites: []PathRewrite{
+				{Prefix: "/build/src/", Replacement: "/PROJECT_ROOT"},
+				{Prefix: filepath.Join(wd, "internal"), Replacement: "/INTERNAL"},
+			},
+		}
+		Expect(rewrite(opt,
+			"/build/src/main.go",
+			"/build/srcfoo/main.go",
+			filepath.Join(wd, "internal", "file.go"),
+		)).To(Equal([]string{
+			"/PROJECT_ROOT/main.go",
+			"/build/srcfoo/main.go",
+			"/INTERNAL/file.go",
+		}))
+	})
+})
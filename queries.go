@@ -19,6 +19,9 @@ type QueryInfo struct {
 	Line      int
 	StartTime time.Time
 	EndTime   time.Time
+
+	// Dialect is the SQL syntax the query is normalized with.
+	Dialect QueryDialect
 }
 
 type queryKey struct {
@@ -40,6 +43,7 @@ type queryStats struct {
 	opt        *NotifierOptions
 	flushTimer *time.Timer
 	addWG      *sync.WaitGroup
+	limit      *keyLimit
 
 	mu sync.Mutex
 	m  map[queryKey]*tdigestStat
@@ -47,22 +51,28 @@ type queryStats struct {
 
 func newQueryStats(opt *NotifierOptions) *queryStats {
 	return &queryStats{
-		opt: opt,
+		opt:   opt,
+		limit: newKeyLimit("queryStats", opt),
 	}
 }
 
 func (s *queryStats) init() {
 	if s.flushTimer == nil {
-		s.flushTimer = time.AfterFunc(flushPeriod, s.flush)
+		s.flushTimer = time.AfterFunc(flushPeriod, s.Flush)
 		s.addWG = new(sync.WaitGroup)
 		s.m = make(map[queryKey]*tdigestStat)
+		s.limit.reset()
 	}
 }
 
-func (s *queryStats) flush() {
+// Flush sends to Airbrake query stats collected since the last flush.
+func (s *queryStats) Flush() {
 	s.mu.Lock()
 
-	s.flushTimer = nil
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
 	addWG := s.addWG
 	s.addWG = nil
 	m := s.m
@@ -70,6 +80,10 @@ func (s *queryStats) flush() {
 
 	s.mu.Unlock()
 
+	if m == nil {
+		return
+	}
+
 	addWG.Wait()
 	err := s.send(m)
 	if err != nil {
@@ -157,10 +171,15 @@ func (s *queryStats) Notify(c context.Context, q *QueryInfo) error {
 		)
 	}
 
+	query := q.Query
+	if !s.opt.DisableQueryNormalization {
+		query = NormalizeQueryDialect(query, q.Dialect)
+	}
+
 	key := queryKey{
 		Method: q.Method,
 		Route:  q.Route,
-		Query:  q.Query,
+		Query:  query,
 		Func:   q.Func,
 		File:   q.File,
 		Line:   q.Line,
@@ -170,6 +189,17 @@ func (s *queryStats) Notify(c context.Context, q *QueryInfo) error {
 	s.mu.Lock()
 	s.init()
 	stat, ok := s.m[key]
+	if !ok && s.limit.overflow(len(s.m), key) {
+		key = queryKey{
+			Method: otherKey,
+			Route:  otherKey,
+			Query:  otherKey,
+			Func:   otherKey,
+			File:   otherKey,
+			Time:   key.Time,
+		}
+		stat, ok = s.m[key]
+	}
 	if !ok {
 		stat = newTDigestStat()
 		s.m[key] = stat
@@ -0,0 +1,59 @@
+package gobrake
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// newStatsNotifier returns a notifier whose stats are accepted by a test
+// server.
+func newStatsNotifier() *Notifier {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+	return NewNotifierWithOptions(&NotifierOptions{
+		ProjectId:        1,
+		ProjectKey:       "key",
+		Host:             server.URL,
+		RemoteConfigHost: server.URL,
+	})
+}
+
+var _ = Describe("queryStats", func() {
+	var notifier *Notifier
+
+	BeforeEach(func() {
+		notifier = newStatsNotifier()
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("stops the flush timer on Flush", func() {
+		err := notifier.Queries.Notify(context.TODO(), &QueryInfo{
+			Query:     "SELECT 1",
+			StartTime: time.Now(),
+			EndTime:   time.Now(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		notifier.Queries.mu.Lock()
+		timer := notifier.Queries.flushTimer
+		notifier.Queries.mu.Unlock()
+
+		notifier.Queries.Flush()
+		Expect(timer.Stop()).To(BeFalse())
+	})
+})
@@ -0,0 +1,406 @@
+package gobrake
+
+import (
+	"strings"
+)
+
+// NormalizeQuery returns the query with literals and placeholders replaced
+// by ? to keep the number of distinct queries small and to not report
+// values, e.g.
+//
+//	SELECT * FROM users WHERE id IN (1, 2, 3) AND name = 'bob'
+//
+// becomes
+//
+//	SELECT * FROM users WHERE id IN (?) AND name = ?
+//
+// In detail:
+//   - string, dollar-quoted, numeric, hex and bit literals become ?;
+//   - PostgreSQL $1, MySQL ? and SQLite ?NNN, :name, @name and $name
+//     placeholders become ?;
+//   - IN lists and VALUES tuples that only differ in length are collapsed
+//     to the first element;
+//   - comments are removed and whitespace is collapsed to single spaces.
+//
+// Identifiers, keywords and operators are kept as is. Double-quoted strings
+// are identifiers and backslashes escape quotes only in E'...' strings like
+// in standard SQL and PostgreSQL. Use NormalizeQueryDialect for MySQL.
+func NormalizeQuery(query string) string {
+	return NormalizeQueryDialect(query, QueryDialectDefault)
+}
+
+// QueryDialect selects the SQL syntax NormalizeQueryDialect assumes.
+type QueryDialect int
+
+const (
+	// QueryDialectDefault is standard SQL as used by PostgreSQL and SQLite.
+	QueryDialectDefault QueryDialect = iota
+	// QueryDialectMySQL treats double-quoted strings as literals, honors
+	// backslash escapes in all strings and # comments anywhere.
+	QueryDialectMySQL
+)
+
+// NormalizeQueryDialect is like NormalizeQuery for the dialect.
+func NormalizeQueryDialect(query string, dialect QueryDialect) string {
+	tokens := tokenizeQuery(query, dialect == QueryDialectMySQL)
+	tokens = collapseLists(tokens)
+
+	var sb strings.Builder
+	sb.Grow(len(query))
+	for i, t := range tokens {
+		if i > 0 && t.space {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+type queryTokenKind int
+
+const (
+	queryWord queryTokenKind = iota
+	queryValue
+	queryPunct
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	text  string
+	space bool // whether the token is preceded by whitespace or a comment
+}
+
+// Keywords after which + and - are signs of numbers and not operators.
+var signKeywords = map[string]bool{
+	"AND": true, "BETWEEN": true, "ELSE": true, "IN": true, "IS": true,
+	"LIKE": true, "LIMIT": true, "NOT": true, "OFFSET": true, "OR": true,
+	"RETURN": true, "SELECT": true, "SET": true, "THEN": true,
+	"VALUES": true, "WHEN": true, "WHERE": true,
+}
+
+func tokenizeQuery(s string, mysql bool) []queryToken {
+	var tokens []queryToken
+	space := false
+	lineStart := true
+
+	add := func(kind queryTokenKind, text string) {
+		tokens = append(tokens, queryToken{kind: kind, text: text, space: space})
+		space = false
+		lineStart = false
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\n':
+			space = true
+			lineStart = true
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			space = true
+			i++
+		case c == '-' && strings.HasPrefix(s[i:], "--"),
+			// Without the MySQL dialect # comments are only recognized at
+			// the start of a line, because # is an operator in PostgreSQL.
+			c == '#' && (mysql || lineStart):
+			end := strings.IndexByte(s[i:], '\n')
+			if end == -1 {
+				end = len(s) - i
+			}
+			space = true
+			i += end
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end == -1 {
+				i = len(s)
+			} else {
+				i += 2 + end + 2
+			}
+			space = true
+		case c == '\'' || c == '"' && mysql:
+			i = skipQuoted(s, i, c, mysql)
+			add(queryValue, "?")
+		case c == '"' || c == '`':
+			// Quoted identifiers.
+			end := skipQuoted(s, i, c, false)
+			add(queryWord, s[i:end])
+			i = end
+		case isDigit(c) || c == '.' && i+1 < len(s) && isDigit(s[i+1]) && !afterOperand(tokens):
+			i = skipNumber(s, i)
+			add(queryValue, "?")
+		case (c == '-' || c == '+') && i+1 < len(s) &&
+			(isDigit(s[i+1]) || s[i+1] == '.') && isSign(tokens):
+			i = skipNumber(s, i+1)
+			add(queryValue, "?")
+		case c == '$':
+			if end, ok := skipDollarQuoted(s, i); ok {
+				i = end
+				add(queryValue, "?")
+				break
+			}
+			end := i + 1
+			for end < len(s) && isIdentChar(s[end]) {
+				end++
+			}
+			if end == i+1 {
+				add(queryPunct, "$")
+			} else {
+				add(queryValue, "?")
+			}
+			i = end
+		case c == '?':
+			i++
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+			add(queryValue, "?")
+		case c == ':' && strings.HasPrefix(s[i:], "::"):
+			add(queryPunct, "::")
+			i += 2
+		case (c == ':' || c == '@') && i+1 < len(s) && isIdentStart(s[i+1]):
+			i++
+			for i < len(s) && isIdentChar(s[i]) {
+				i++
+			}
+			add(queryValue, "?")
+		case c == '@' && strings.HasPrefix(s[i:], "@@"):
+			// MySQL system variables.
+			end := i + 2
+			for end < len(s) && (isIdentChar(s[end]) || s[end] == '.') {
+				end++
+			}
+			add(queryWord, s[i:end])
+			i = end
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(s) && isIdentChar(s[end]) {
+				end++
+			}
+			word := s[i:end]
+			// E'...', N'...', X'...' and B'...' literals.
+			if end < len(s) && s[end] == '\'' && len(word) == 1 &&
+				strings.ContainsAny(word, "EeNnXxBb") {
+				i = skipQuoted(s, end, '\'', mysql || word == "E" || word == "e")
+				add(queryValue, "?")
+				break
+			}
+			add(queryWord, word)
+			i = end
+		default:
+			add(queryPunct, s[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipQuoted returns the index after the literal that starts at s[i].
+// Quotes are escaped by doubling them and, if backslash is set, by a
+// backslash.
+func skipQuoted(s string, i int, quote byte, backslash bool) int {
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// skipDollarQuoted skips PostgreSQL $$...$$ and $tag$...$tag$ literals.
+func skipDollarQuoted(s string, i int) (int, bool) {
+	end := i + 1
+	for end < len(s) && isIdentChar(s[end]) && s[end] != '$' {
+		end++
+	}
+	if end >= len(s) || s[end] != '$' || end > i+1 && isDigit(s[i+1]) {
+		return 0, false
+	}
+	tag := s[i : end+1]
+	n := strings.Index(s[end+1:], tag)
+	if n == -1 {
+		return 0, false
+	}
+	return end + 1 + n + len(tag), true
+}
+
+func skipNumber(s string, i int) int {
+	if strings.HasPrefix(s[i:], "0x") || strings.HasPrefix(s[i:], "0X") {
+		i += 2
+		for i < len(s) && isHexDigit(s[i]) {
+			i++
+		}
+		return i
+	}
+	for i < len(s) && (isDigit(s[i]) || s[i] == '.') {
+		i++
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '+' || s[j] == '-') {
+			j++
+		}
+		if j < len(s) && isDigit(s[j]) {
+			i = j
+			for i < len(s) && isDigit(s[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+// afterOperand reports whether the last token ends an operand, e.g. a
+// column name, so that a following . can't start a number.
+func afterOperand(tokens []queryToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	return last.kind != queryPunct || last.text == ")" || last.text == "]"
+}
+
+// isSign reports whether + or - after the tokens is a sign of a number
+// rather than an operator.
+func isSign(tokens []queryToken) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	switch last.kind {
+	case queryPunct:
+		return last.text != ")" && last.text != "]"
+	case queryWord:
+		return signKeywords[strings.ToUpper(last.text)]
+	}
+	return false
+}
+
+// collapseLists keeps only the first element of IN lists and VALUES tuples
+// when all elements are the same after normalization, e.g. IN (?, ?, ?)
+// becomes IN (?) and VALUES (?, ?), (?, ?) becomes VALUES (?, ?).
+func collapseLists(tokens []queryToken) []queryToken {
+	out := tokens[:0:0]
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		out = append(out, t)
+		if t.kind != queryWord {
+			continue
+		}
+
+		switch strings.ToUpper(t.text) {
+		case "IN":
+			if i+1 >= len(tokens) || tokens[i+1].text != "(" {
+				continue
+			}
+			end := matchParen(tokens, i+1)
+			if end == -1 {
+				continue
+			}
+			items := splitItems(tokens[i+2 : end])
+			if len(items) > 1 && allEqual(items) {
+				out = append(out, tokens[i+1])
+				out = append(out, items[0]...)
+				out = append(out, tokens[end])
+				i = end
+			}
+		case "VALUES":
+			var items [][]queryToken
+			j := i + 1
+			for j < len(tokens) && tokens[j].text == "(" {
+				end := matchParen(tokens, j)
+				if end == -1 {
+					break
+				}
+				items = append(items, tokens[j:end+1])
+				j = end + 1
+				if j+1 < len(tokens) && tokens[j].text == "," && tokens[j+1].text == "(" {
+					j++
+					continue
+				}
+				break
+			}
+			if len(items) > 1 && allEqual(items) {
+				out = append(out, items[0]...)
+				i = j - 1
+			}
+		}
+	}
+	return out
+}
+
+// matchParen returns the index of the parenthesis that closes tokens[i].
+func matchParen(tokens []queryToken, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitItems splits tokens by commas that are not nested in parentheses.
+func splitItems(tokens []queryToken) [][]queryToken {
+	var items [][]queryToken
+	depth := 0
+	start := 0
+	for i, t := range tokens {
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ",":
+			if depth == 0 {
+				items = append(items, tokens[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(items, tokens[start:])
+}
+
+func allEqual(items [][]queryToken) bool {
+	for _, item := range items[1:] {
+		if len(item) != len(items[0]) {
+			return false
+		}
+		for i, t := range item {
+			if t.text != items[0][i].text {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
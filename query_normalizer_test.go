@@ -0,0 +1,208 @@
+package gobrake_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+var _ = Describe("NormalizeQuery", func() {
+	It("replaces literals and placeholders", func() {
+		var tests = []struct {
+			query string
+			want  string
+		}{
+			{"SELECT * FROM users", "SELECT * FROM users"},
+			{"SELECT * FROM users WHERE id = 123", "SELECT * FROM users WHERE id = ?"},
+			{"SELECT * FROM users WHERE id=-1.5e10", "SELECT * FROM users WHERE id=?"},
+			{"SELECT a - 1, b+.5 FROM t", "SELECT a - ?, b+? FROM t"},
+			{"SELECT * FROM t1 WHERE t1.c2 = 0x1F", "SELECT * FROM t1 WHERE t1.c2 = ?"},
+			{"SELECT * FROM users WHERE name = 'bob'", "SELECT * FROM users WHERE name = ?"},
+			{`SELECT * FROM users WHERE name = 'o''brien' OR name = E'o\'brien'`, "SELECT * FROM users WHERE name = ? OR name = ?"},
+			{`SELECT * FROM files WHERE path = 'C:\' AND id = 5`, "SELECT * FROM files WHERE path = ? AND id = ?"},
+			{"SELECT E'\\n', N'abc', X'ff', B'101'", "SELECT ?, ?, ?, ?"},
+			{"SELECT $$it's$$, $tag$a $$ b$tag$", "SELECT ?, ?"},
+			{`SELECT "col 1", ` + "`col 2`" + ` FROM "t"`, `SELECT "col 1", ` + "`col 2`" + ` FROM "t"`},
+			{"SELECT * FROM users WHERE id = $1 AND name = $2", "SELECT * FROM users WHERE id = ? AND name = ?"},
+			{"SELECT * FROM users WHERE id = ? AND name = ?2", "SELECT * FROM users WHERE id = ? AND name = ?"},
+			{"SELECT * FROM users WHERE id = :id AND name = @name AND age = $age", "SELECT * FROM users WHERE id = ? AND name = ? AND age = ?"},
+			{"SELECT id::text, @@session.time_zone FROM users", "SELECT id::text, @@session.time_zone FROM users"},
+			{"SELECT data #> '{a}' FROM t", "SELECT data #> ? FROM t"},
+		}
+
+		for _, test := range tests {
+			Expect(gobrake.NormalizeQuery(test.query)).To(Equal(test.want), test.query)
+		}
+	})
+
+	It("collapses IN lists and VALUES tuples", func() {
+		var tests = []struct {
+			query string
+			want  string
+		}{
+			{"SELECT * FROM users WHERE id IN (1, 2, 3)", "SELECT * FROM users WHERE id IN (?)"},
+			{"SELECT * FROM users WHERE id in ($1,$2)", "SELECT * FROM users WHERE id in (?)"},
+			{"SELECT * FROM users WHERE id NOT IN ('a')", "SELECT * FROM users WHERE id NOT IN (?)"},
+			{"SELECT * FROM t WHERE (a, b) IN ((1, 2), (3, 4))", "SELECT * FROM t WHERE (a, b) IN ((?, ?))"},
+			{"SELECT * FROM t WHERE a IN (b, c)", "SELECT * FROM t WHERE a IN (b, c)"},
+			{"SELECT * FROM t WHERE a IN (SELECT id FROM u WHERE x = 1)", "SELECT * FROM t WHERE a IN (SELECT id FROM u WHERE x = ?)"},
+			{"INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'z')", "INSERT INTO t (a, b) VALUES (?, ?)"},
+			{"INSERT INTO t VALUES (?, NOW()), (?, NOW()) ON CONFLICT DO NOTHING", "INSERT INTO t VALUES (?, NOW()) ON CONFLICT DO NOTHING"},
+			{"INSERT INTO t VALUES (1, NULL), (2, DEFAULT)", "INSERT INTO t VALUES (?, NULL), (?, DEFAULT)"},
+		}
+
+		for _, test := range tests {
+			Expect(gobrake.NormalizeQuery(test.query)).To(Equal(test.want), test.query)
+		}
+	})
+
+	It("removes comments and collapses whitespace", func() {
+		var tests = []struct {
+			query string
+			want  string
+		}{
+			{"  SELECT *\n\tFROM   users  ", "SELECT * FROM users"},
+			{"SELECT * -- all\nFROM users", "SELECT * FROM users"},
+			{"SELECT */* all */FROM users", "SELECT * FROM users"},
+			{"# users\nSELECT * FROM users", "SELECT * FROM users"},
+			{"SELECT 1 /* unterminated", "SELECT ?"},
+		}
+
+		for _, test := range tests {
+			Expect(gobrake.NormalizeQuery(test.query)).To(Equal(test.want), test.query)
+		}
+	})
+
+	It("supports MySQL strings and comments", func() {
+		var tests = []struct {
+			query string
+			want  string
+		}{
+			{`SELECT * FROM u WHERE name = "bob"`, "SELECT * FROM u WHERE name = ?"},
+			{`SELECT * FROM u WHERE name = "say \"hi\"" OR name = 'o\'brien'`, "SELECT * FROM u WHERE name = ? OR name = ?"},
+			{"SELECT * FROM `u` WHERE id = 1 # by id", "SELECT * FROM `u` WHERE id = ?"},
+			{`SELECT * FROM u WHERE name IN ("a", "b")`, "SELECT * FROM u WHERE name IN (?)"},
+		}
+
+		for _, test := range tests {
+			Expect(gobrake.NormalizeQueryDialect(test.query, gobrake.QueryDialectMySQL)).
+				To(Equal(test.want), test.query)
+		}
+
+		Expect(gobrake.NormalizeQuery(`SELECT * FROM u WHERE name = "bob"`)).
+			To(Equal(`SELECT * FROM u WHERE name = "bob"`))
+	})
+})
+
+var _ = Describe("queryStats", func() {
+	var opt *gobrake.NotifierOptions
+	var notifier *gobrake.Notifier
+
+	var mu sync.Mutex
+	var queries []string
+
+	BeforeEach(func() {
+		queries = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			var out struct {
+				Queries []struct {
+					Query string `json:"query"`
+				} `json:"queries"`
+			}
+			err = json.Unmarshal(b, &out)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			for _, q := range out.Queries {
+				queries = append(queries, q.Query)
+			}
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		opt = &gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		}
+	})
+
+	JustBeforeEach(func() {
+		notifier = gobrake.NewNotifierWithOptions(opt)
+
+		for _, query := range []string{
+			"SELECT * FROM users WHERE id IN (1, 2)",
+			"SELECT * FROM users WHERE id IN (3, 4, 5)",
+		} {
+			err := notifier.Queries.Notify(context.TODO(), &gobrake.QueryInfo{
+				Query:     query,
+				StartTime: time.Now(),
+				EndTime:   time.Now(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		notifier.Queries.Flush()
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("normalizes queries", func() {
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(queries).To(ConsistOf("SELECT * FROM users WHERE id IN (?)"))
+	})
+
+	It("normalizes queries of the dialect", func() {
+		err := notifier.Queries.Notify(context.TODO(), &gobrake.QueryInfo{
+			Query:     `SELECT * FROM users WHERE name = "bob"`,
+			Dialect:   gobrake.QueryDialectMySQL,
+			StartTime: time.Now(),
+			EndTime:   time.Now(),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		notifier.Queries.Flush()
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(queries).To(ContainElement("SELECT * FROM users WHERE name = ?"))
+	})
+
+	Context("when DisableQueryNormalization is true", func() {
+		BeforeEach(func() {
+			opt.DisableQueryNormalization = true
+		})
+
+		It("sends queries as is", func() {
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(queries).To(ConsistOf(
+				"SELECT * FROM users WHERE id IN (1, 2)",
+				"SELECT * FROM users WHERE id IN (3, 4, 5)",
+			))
+		})
+	})
+})
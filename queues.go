@@ -35,6 +35,7 @@ type queueStats struct {
 	opt        *NotifierOptions
 	flushTimer *time.Timer
 	addWG      *sync.WaitGroup
+	limit      *keyLimit
 
 	mu sync.Mutex
 	m  map[queueKey]*queueBreakdown
@@ -42,7 +43,8 @@ type queueStats struct {
 
 func newQueueStats(opt *NotifierOptions) *queueStats {
 	return &queueStats{
-		opt: opt,
+		opt:   opt,
+		limit: newKeyLimit("queueStats", opt),
 	}
 }
 
@@ -51,13 +53,17 @@ func (s *queueStats) init() {
 		s.flushTimer = time.AfterFunc(flushPeriod, s.flush)
 		s.addWG = new(sync.WaitGroup)
 		s.m = make(map[queueKey]*queueBreakdown)
+		s.limit.reset()
 	}
 }
 
 func (s *queueStats) flush() {
 	s.mu.Lock()
 
-	s.flushTimer = nil
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
 	addWG := s.addWG
 	s.addWG = nil
 	m := s.m
@@ -162,6 +168,10 @@ func (s *queueStats) Notify(c context.Context, metric *QueueMetric) error {
 	s.mu.Lock()
 	s.init()
 	b, ok := s.m[key]
+	if !ok && s.limit.overflow(len(s.m), key) {
+		key = queueKey{Queue: otherKey, Time: key.Time}
+		b, ok = s.m[key]
+	}
 	if !ok {
 		b = &queueBreakdown{
 			queueKey: key,
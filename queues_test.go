@@ -0,0 +1,33 @@
+package gobrake
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("queueStats", func() {
+	var notifier *Notifier
+
+	BeforeEach(func() {
+		notifier = newStatsNotifier()
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("stops the flush timer on flush", func() {
+		c, metric := NewQueueMetric(context.TODO(), "queue")
+		err := notifier.Queues.Notify(c, metric)
+		Expect(err).NotTo(HaveOccurred())
+
+		notifier.Queues.mu.Lock()
+		timer := notifier.Queues.flushTimer
+		notifier.Queues.mu.Unlock()
+
+		notifier.Queues.flush()
+		Expect(timer.Stop()).To(BeFalse())
+	})
+})
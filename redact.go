@@ -0,0 +1,243 @@
+package gobrake
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const filteredValue = "[Filtered]"
+
+type valueRedactor func(string) string
+
+var defaultValueRedactors = []valueRedactor{
+	redactURLPasswords,
+	redactJWTs,
+	redactBearerTokens,
+	redactAWSKeys,
+	redactEmails,
+	redactCreditCards,
+}
+
+var (
+	urlPasswordRe  = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.\-]*://[^/\s:@]+:)[^/\s@]+@`)
+	jwtRe          = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	bearerTokenRe  = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	awsAccessKeyRe = regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b`)
+	awsSecretKeyRe = regexp.MustCompile(`(?i)(aws_?secret_?access_?key["']?\s*[=:]\s*["']?)[A-Za-z0-9/+=]{40}`)
+	emailRe        = regexp.MustCompile(`(^|[^/\w.%+\-])[\w.%+\-]+@[\w\-]+(?:\.[\w\-]+)*\.[A-Za-z]{2,}`)
+	creditCardRe   = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+)
+
+func redactURLPasswords(s string) string {
+	return urlPasswordRe.ReplaceAllString(s, "${1}"+filteredValue+"@")
+}
+
+func redactJWTs(s string) string {
+	return jwtRe.ReplaceAllString(s, filteredValue)
+}
+
+func redactBearerTokens(s string) string {
+	return bearerTokenRe.ReplaceAllString(s, "${1}"+filteredValue)
+}
+
+func redactAWSKeys(s string) string {
+	s = awsAccessKeyRe.ReplaceAllString(s, filteredValue)
+	return awsSecretKeyRe.ReplaceAllString(s, "${1}"+filteredValue)
+}
+
+func redactEmails(s string) string {
+	return emailRe.ReplaceAllString(s, "${1}"+filteredValue)
+}
+
+func redactCreditCards(s string) string {
+	return creditCardRe.ReplaceAllStringFunc(s, func(match string) string {
+		digits, ok := cardDigits(match)
+		if ok && cardIINValid(digits) && luhnValid(digits) {
+			return filteredValue
+		}
+		return match
+	})
+}
+
+// Digit groups of card numbers that are written with separators.
+var cardGroups = [][]int{
+	{4, 4, 4, 4},
+	{4, 4, 4, 4, 3},
+	{4, 6, 5},
+	{4, 6, 4},
+}
+
+// cardDigits returns the digits of s. Numbers with separators must use the
+// same separator between all digit groups and a known grouping, so lists
+// and ranges of numbers aren't taken for card numbers.
+func cardDigits(s string) (string, bool) {
+	if !strings.ContainsAny(s, " -") {
+		return s, true
+	}
+
+	sep := " "
+	if strings.Contains(s, "-") {
+		if strings.Contains(s, " ") {
+			return "", false
+		}
+		sep = "-"
+	}
+
+	groups := strings.Split(s, sep)
+	for _, want := range cardGroups {
+		if len(groups) != len(want) {
+			continue
+		}
+		ok := true
+		for i, g := range groups {
+			if len(g) != want[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return strings.Join(groups, ""), true
+		}
+	}
+	return "", false
+}
+
+// cardIINValid reports whether digits start with the issuer identification
+// number of a major card network and have a length used by that network.
+func cardIINValid(digits string) bool {
+	n := len(digits)
+	prefix := func(k int) int {
+		v := 0
+		for i := 0; i < k; i++ {
+			v = v*10 + int(digits[i]-'0')
+		}
+		return v
+	}
+
+	switch p1, p2, p3, p4 := prefix(1), prefix(2), prefix(3), prefix(4); {
+	case p1 == 4: // Visa
+		return n == 13 || n == 16 || n == 19
+	case p2 >= 51 && p2 <= 55, p4 >= 2221 && p4 <= 2720: // Mastercard
+		return n == 16
+	case p2 == 34, p2 == 37: // American Express
+		return n == 15
+	case p4 == 6011, p2 == 65, p3 >= 644 && p3 <= 649: // Discover
+		return n >= 16
+	case p4 >= 3528 && p4 <= 3589: // JCB
+		return n >= 16
+	case p2 == 36, p2 == 38, p2 == 39, p3 >= 300 && p3 <= 305: // Diners Club
+		return n >= 14
+	case p2 == 62: // UnionPay
+		return n >= 16
+	}
+	return false
+}
+
+// luhnValid reports whether digits pass the Luhn checksum.
+func luhnValid(digits string) bool {
+	var sum int
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func newValueRedactor(value interface{}) valueRedactor {
+	switch value := value.(type) {
+	case string:
+		return func(s string) string {
+			return strings.Replace(s, value, filteredValue, -1)
+		}
+	case *regexp.Regexp:
+		return func(s string) string {
+			return value.ReplaceAllString(s, filteredValue)
+		}
+	default:
+		panic(fmt.Errorf("unsupported blocklist value type: %T", value))
+	}
+}
+
+// NewRedactValuesFilter returns a filter that replaces secrets and personal
+// data found in error messages and in string values of the notice context,
+// environment, session and params with the [Filtered] label.
+//
+// JWTs, bearer tokens, credit card numbers, email addresses, AWS keys and
+// passwords in URLs are redacted by default. Additional values can be passed
+// as strings or *regexp.Regexp.
+func NewRedactValuesFilter(values ...interface{}) func(*Notice) *Notice {
+	redactors := append([]valueRedactor(nil), defaultValueRedactors...)
+	for _, v := range values {
+		redactors = append(redactors, newValueRedactor(v))
+	}
+
+	redact := func(s string) string {
+		for _, fn := range redactors {
+			s = fn(s)
+		}
+		return s
+	}
+
+	return func(notice *Notice) *Notice {
+		for i := range notice.Errors {
+			notice.Errors[i].Message = redact(notice.Errors[i].Message)
+		}
+
+		redactMap(notice.Context, redact)
+		redactMap(notice.Env, redact)
+		redactMap(notice.Session, redact)
+		redactMap(notice.Params, redact)
+
+		return notice
+	}
+}
+
+// redactMap redacts values of m in place. Nested maps and slices are copied
+// because they may be shared with other notices, e.g. the default context.
+func redactMap(m map[string]interface{}, redact func(string) string) {
+	for k, v := range m {
+		m[k] = redactValue(v, redact)
+	}
+}
+
+func redactValue(v interface{}, redact func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return redact(v)
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = redact(s)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, el := range v {
+			out[i] = redactValue(el, redact)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, el := range v {
+			out[k] = redactValue(el, redact)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for k, s := range v {
+			out[k] = redact(s)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,6 +24,7 @@ type remoteConfig struct {
 	opt    *NotifierOptions
 	ticker *time.Ticker
 
+	mu   sync.RWMutex
 	JSON *RemoteConfigJSON
 }
 
@@ -72,17 +74,35 @@ func (rc *remoteConfig) Poll() {
 }
 
 func (rc *remoteConfig) tick() error {
+	_, err := rc.fetch()
+	return err
+}
+
+// fetch parses the config into a new struct and swaps it in, so the config
+// is never modified while it is being read.
+func (rc *remoteConfig) fetch() (*RemoteConfigJSON, error) {
 	body, err := fetchConfig(rc.ConfigRoute(rc.opt.RemoteConfigHost))
 	if err != nil {
-		return fmt.Errorf("fetchConfig failed: %s", err)
+		return nil, fmt.Errorf("fetchConfig failed: %s", err)
 	}
 
-	err = json.Unmarshal(body, rc.JSON)
+	cfg := new(RemoteConfigJSON)
+	err = json.Unmarshal(body, cfg)
 	if err != nil {
-		return fmt.Errorf("parseConfig failed: %s", err)
+		return nil, fmt.Errorf("parseConfig failed: %s", err)
 	}
 
-	return nil
+	rc.mu.Lock()
+	rc.JSON = cfg
+	rc.mu.Unlock()
+
+	return cfg, nil
+}
+
+func (rc *remoteConfig) config() *RemoteConfigJSON {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.JSON
 }
 
 func (rc *remoteConfig) StopPolling() {
@@ -92,18 +112,18 @@ func (rc *remoteConfig) StopPolling() {
 }
 
 func (rc *remoteConfig) Interval() time.Duration {
-	if rc.JSON.PollSec > 0 {
-		return time.Duration(rc.JSON.PollSec) * time.Second
+	if cfg := rc.config(); cfg.PollSec > 0 {
+		return time.Duration(cfg.PollSec) * time.Second
 	}
 
 	return defaultInterval
 }
 
 func (rc *remoteConfig) ConfigRoute(remoteConfigHost string) string {
-	if rc.JSON.ConfigRoute != "" {
+	if cfg := rc.config(); cfg.ConfigRoute != "" {
 		return fmt.Sprintf("%s/%s",
 			strings.TrimSuffix(remoteConfigHost, "/"),
-			rc.JSON.ConfigRoute)
+			cfg.ConfigRoute)
 	}
 
 	return fmt.Sprintf(configRoutePattern,
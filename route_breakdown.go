@@ -26,6 +26,7 @@ type routeBreakdowns struct {
 	opt        *NotifierOptions
 	flushTimer *time.Timer
 	addWG      *sync.WaitGroup
+	limit      *keyLimit
 
 	mu sync.Mutex
 	m  map[routeBreakdownKey]*routeBreakdown
@@ -33,7 +34,8 @@ type routeBreakdowns struct {
 
 func newRouteBreakdowns(opt *NotifierOptions) *routeBreakdowns {
 	return &routeBreakdowns{
-		opt: opt,
+		opt:   opt,
+		limit: newKeyLimit("routeBreakdowns", opt),
 	}
 }
 
@@ -42,6 +44,7 @@ func (s *routeBreakdowns) init() {
 		s.flushTimer = time.AfterFunc(flushPeriod, s.Flush)
 		s.addWG = new(sync.WaitGroup)
 		s.m = make(map[routeBreakdownKey]*routeBreakdown)
+		s.limit.reset()
 	}
 }
 
@@ -49,7 +52,10 @@ func (s *routeBreakdowns) init() {
 func (s *routeBreakdowns) Flush() {
 	s.mu.Lock()
 
-	s.flushTimer = nil
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
 	addWG := s.addWG
 	s.addWG = nil
 	m := s.m
@@ -162,6 +168,15 @@ func (s *routeBreakdowns) Notify(c context.Context, metric *RouteMetric) error {
 	s.mu.Lock()
 	s.init()
 	b, ok := s.m[key]
+	if !ok && s.limit.overflow(len(s.m), key) {
+		key = routeBreakdownKey{
+			Method:   otherKey,
+			Route:    otherKey,
+			RespType: key.RespType,
+			Time:     key.Time,
+		}
+		b, ok = s.m[key]
+	}
 	if !ok {
 		b = &routeBreakdown{
 			routeBreakdownKey: key,
@@ -32,6 +32,7 @@ type routeStats struct {
 	opt        *NotifierOptions
 	flushTimer *time.Timer
 	addWG      *sync.WaitGroup
+	limit      *keyLimit
 
 	mu sync.Mutex
 	m  map[routeKey]*tdigestStat
@@ -39,7 +40,8 @@ type routeStats struct {
 
 func newRouteStats(opt *NotifierOptions) *routeStats {
 	return &routeStats{
-		opt: opt,
+		opt:   opt,
+		limit: newKeyLimit("routeStats", opt),
 	}
 }
 
@@ -48,6 +50,7 @@ func (s *routeStats) init() {
 		s.flushTimer = time.AfterFunc(flushPeriod, s.Flush)
 		s.addWG = new(sync.WaitGroup)
 		s.m = make(map[routeKey]*tdigestStat)
+		s.limit.reset()
 	}
 }
 
@@ -55,7 +58,10 @@ func (s *routeStats) init() {
 func (s *routeStats) Flush() {
 	s.mu.Lock()
 
-	s.flushTimer = nil
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+		s.flushTimer = nil
+	}
 	addWG := s.addWG
 	s.addWG = nil
 	m := s.m
@@ -165,6 +171,15 @@ func (s *routeStats) Notify(c context.Context, req *RouteMetric) error {
 	s.mu.Lock()
 	s.init()
 	stat, ok := s.m[key]
+	if !ok && s.limit.overflow(len(s.m), key) {
+		key = routeKey{
+			Method:     otherKey,
+			Route:      otherKey,
+			StatusCode: key.StatusCode,
+			Time:       key.Time,
+		}
+		stat, ok = s.m[key]
+	}
 	if !ok {
 		stat = newTDigestStat()
 		s.m[key] = stat
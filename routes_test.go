@@ -0,0 +1,39 @@
+package gobrake
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("routeStats", func() {
+	var notifier *Notifier
+
+	BeforeEach(func() {
+		notifier = newStatsNotifier()
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("stops the flush timers of stats and breakdowns on Flush", func() {
+		c, metric := NewRouteMetric(context.TODO(), "GET", "/")
+		metric.StatusCode = http.StatusOK
+		err := notifier.Routes.Notify(c, metric)
+		Expect(err).NotTo(HaveOccurred())
+
+		notifier.Routes.stats.mu.Lock()
+		statsTimer := notifier.Routes.stats.flushTimer
+		notifier.Routes.stats.mu.Unlock()
+		notifier.Routes.breakdowns.mu.Lock()
+		breakdownsTimer := notifier.Routes.breakdowns.flushTimer
+		notifier.Routes.breakdowns.mu.Unlock()
+
+		notifier.Routes.Flush()
+		Expect(statsTimer.Stop()).To(BeFalse())
+		Expect(breakdownsTimer.Stop()).To(BeFalse())
+	})
+})
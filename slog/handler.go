@@ -0,0 +1,245 @@
+package slog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+// messageParam is the param that holds the record message when an error
+// attribute is reported instead.
+const messageParam = "slog.message"
+
+type HandlerOptions struct {
+	// Minimum level of records that are reported to Airbrake.
+	// Default is slog.LevelError.
+	Level slog.Leveler
+}
+
+// Handler passes records to the inner handler and reports records at or
+// above the configured level to Airbrake.
+type Handler struct {
+	inner    slog.Handler
+	notifier *gobrake.Notifier
+	level    slog.Leveler
+
+	// Attributes added with WithAttrs and the groups they belong to.
+	bound  []boundAttrs
+	groups []string
+}
+
+type boundAttrs struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+func NewHandler(inner slog.Handler, notifier *gobrake.Notifier, opt *HandlerOptions) *Handler {
+	h := &Handler{
+		inner:    inner,
+		notifier: notifier,
+		level:    slog.LevelError,
+	}
+	if opt != nil && opt.Level != nil {
+		h.level = opt.Level
+	}
+	return h
+}
+
+func (h *Handler) Enabled(c context.Context, level slog.Level) bool {
+	return level >= h.level.Level() || h.inner.Enabled(c, level)
+}
+
+func (h *Handler) Handle(c context.Context, r slog.Record) error {
+	if r.Level >= h.level.Level() {
+		h.notify(c, r)
+	}
+	if !h.inner.Enabled(c, r.Level) {
+		return nil
+	}
+	return h.inner.Handle(c, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := h.clone()
+	h2.inner = h.inner.WithAttrs(attrs)
+	h2.bound = append(h2.bound, boundAttrs{
+		groups: h.groups,
+		attrs:  attrs,
+	})
+	return h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.inner = h.inner.WithGroup(name)
+	h2.groups = append(h2.groups[:len(h2.groups):len(h2.groups)], name)
+	return h2
+}
+
+func (h *Handler) clone() *Handler {
+	h2 := *h
+	h2.bound = h.bound[:len(h.bound):len(h.bound)]
+	return &h2
+}
+
+func (h *Handler) notify(c context.Context, r slog.Record) {
+	params := make(map[string]interface{})
+	var err error
+
+	for _, b := range h.bound {
+		m := groupMap(params, b.groups)
+		for _, a := range b.attrs {
+			if err == nil {
+				if e, ok := errorValue(a); ok {
+					err = e
+					continue
+				}
+			}
+			addAttr(m, a)
+		}
+	}
+
+	m := groupMap(params, h.groups)
+	r.Attrs(func(a slog.Attr) bool {
+		if e, ok := errorValue(a); ok && err == nil {
+			err = e
+			return true
+		}
+		addAttr(m, a)
+		return true
+	})
+
+	var e interface{} = r.Message
+	if err != nil {
+		e = err
+		if r.Message != "" {
+			// Namespaced to not overwrite an attribute named message.
+			params[messageParam] = r.Message
+		}
+	}
+
+	notice := h.notifier.Notice(e, gobrake.ContextRequest(c), callerDepth(r.PC))
+	for k, v := range params {
+		notice.Params[k] = v
+	}
+	notice.Context["severity"] = severity(r.Level)
+	if metric := gobrake.ContextRouteMetric(c); metric != nil {
+		notice.Context["route"] = metric.Route
+		if _, ok := notice.Context["httpMethod"]; !ok {
+			notice.Context["httpMethod"] = metric.Method
+		}
+	}
+
+	h.notifier.Notify(notice, nil)
+}
+
+// callerDepth returns the depth of the frame that logged the record relative
+// to the caller of callerDepth.
+func callerDepth(pc uintptr) int {
+	const fallback = 2 // the caller of Handle
+
+	if pc == 0 {
+		return fallback
+	}
+
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+	for i, v := range pcs[:n] {
+		if v == pc {
+			return i
+		}
+	}
+	return fallback
+}
+
+func errorValue(a slog.Attr) (error, bool) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindAny {
+		return nil, false
+	}
+	err, ok := v.Any().(error)
+	return err, ok && err != nil
+}
+
+// groupMap returns the nested map for groups creating it if needed.
+func groupMap(m map[string]interface{}, groups []string) map[string]interface{} {
+	for _, g := range groups {
+		child, ok := m[g].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[g] = child
+		}
+		m = child
+	}
+	return m
+}
+
+func addAttr(m map[string]interface{}, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key != "" {
+			m = groupMap(m, []string{a.Key})
+		}
+		for _, a := range attrs {
+			addAttr(m, a)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	m[a.Key] = attrValue(v)
+}
+
+func attrValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time()
+	default:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		return v.Any()
+	}
+}
+
+func severity(level slog.Level) string {
+	switch {
+	case level > slog.LevelError:
+		return "critical"
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
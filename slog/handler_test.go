@@ -0,0 +1,144 @@
+package slog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	"github.com/airbrake/gobrake/v4"
+	slogbrake "github.com/airbrake/gobrake/v4/slog"
+)
+
+func TestSlog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "slog")
+}
+
+func logRequestFailed(logger *slog.Logger) {
+	logger.Error("request failed", "status", 502)
+}
+
+func newConnResetError() error {
+	return errors.New("connection reset")
+}
+
+var _ = Describe("Handler", func() {
+	var notifier *gobrake.Notifier
+	var sentNotice *gobrake.Notice
+	var out *bytes.Buffer
+	var logger *slog.Logger
+
+	BeforeEach(func() {
+		sentNotice = nil
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			sentNotice = new(gobrake.Notice)
+			err = json.Unmarshal(b, sentNotice)
+			Expect(err).To(BeNil())
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"123"}`))
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+
+		out = new(bytes.Buffer)
+		inner := slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo})
+		logger = slog.New(slogbrake.NewHandler(inner, notifier, nil))
+	})
+
+	AfterEach(func() {
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	It("passes records to the inner handler", func() {
+		logger.Info("hello", "user", "bob")
+		notifier.Flush()
+
+		Expect(out.String()).To(ContainSubstring("msg=hello user=bob"))
+		Expect(sentNotice).To(BeNil())
+	})
+
+	It("reports error records", func() {
+		logRequestFailed(logger)
+		notifier.Flush()
+
+		Expect(out.String()).To(ContainSubstring("level=ERROR"))
+		Expect(sentNotice).NotTo(BeNil())
+
+		e := sentNotice.Errors[0]
+		Expect(e.Message).To(Equal("request failed"))
+		Expect(e.Backtrace[0].File).To(Equal("/PROJECT_ROOT/handler_test.go"))
+		Expect(e.Backtrace[0].Func).To(Equal("logRequestFailed"))
+		Expect(sentNotice.Context["severity"]).To(Equal("error"))
+		Expect(sentNotice.Params["status"]).To(Equal(float64(502)))
+	})
+
+	It("uses error attribute as notice error", func() {
+		err := newConnResetError()
+		logger.With("db", "users").WithGroup("req").Error(
+			"query failed", "err", err, slog.Group("q", "table", "t1"), "id", 7,
+		)
+		notifier.Flush()
+
+		e := sentNotice.Errors[0]
+		Expect(e.Type).To(Equal("*errors.fundamental"))
+		Expect(e.Message).To(Equal("connection reset"))
+		Expect(e.Backtrace[0].Func).To(Equal("newConnResetError"))
+		Expect(sentNotice.Params).To(Equal(map[string]interface{}{
+			"slog.message": "query failed",
+			"db":           "users",
+			"req": map[string]interface{}{
+				"id": float64(7),
+				"q": map[string]interface{}{
+					"table": "t1",
+				},
+			},
+		}))
+	})
+
+	It("keeps attributes named message", func() {
+		logger.Error("query failed", "err", newConnResetError(), "message", "hello")
+		notifier.Flush()
+
+		Expect(sentNotice.Params["message"]).To(Equal("hello"))
+		Expect(sentNotice.Params["slog.message"]).To(Equal("query failed"))
+	})
+
+	It("enriches notices from context", func() {
+		req, _ := http.NewRequest("GET", "http://example.com/users/1", nil)
+		c := gobrake.ContextWithRequest(context.Background(), req)
+		c, metric := gobrake.NewRouteMetric(c, "GET", "/users/:id")
+		Expect(metric).NotTo(BeNil())
+
+		logger.ErrorContext(c, "not found")
+		notifier.Flush()
+
+		Expect(sentNotice.Context["url"]).To(Equal("http://example.com/users/1"))
+		Expect(sentNotice.Context["route"]).To(Equal("/users/:id"))
+	})
+})
@@ -0,0 +1,255 @@
+package sqlstats
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+// conn implements the optional interfaces of database/sql connections and
+// falls back to the legacy methods or to driver.ErrSkip when the wrapped
+// connection doesn't implement them, like database/sql does.
+type conn struct {
+	driver.Conn
+	r *reporter
+}
+
+var (
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+)
+
+func newConn(c driver.Conn, r *reporter) *conn {
+	return &conn{
+		Conn: c,
+		r:    r,
+	}
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext reports preparing as "PREPARE <query>" so it doesn't
+// skew stats of the statement executions.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var st driver.Stmt
+	err := c.r.observe(ctx, "PREPARE "+query, func(ctx context.Context) error {
+		var err error
+		if cpc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+			st, err = cpc.PrepareContext(ctx, query)
+		} else {
+			st, err = c.Conn.Prepare(query)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: st, conn: c, query: query}, nil
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	err := c.r.observe(ctx, "BEGIN", func(ctx context.Context) error {
+		if cbt, ok := c.Conn.(driver.ConnBeginTx); ok {
+			var err error
+			tx, err = cbt.BeginTx(ctx, opts)
+			return err
+		}
+
+		// Same checks as database/sql does for drivers without BeginTx.
+		if opts.Isolation != 0 {
+			return errors.New("sql: driver does not support non-default isolation level")
+		}
+		if opts.ReadOnly {
+			return errors.New("sql: driver does not support read-only transactions")
+		}
+		var err error
+		tx, err = c.Conn.Begin()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedTx{Tx: tx, ctx: ctx, r: c.r}, nil
+}
+
+func (c *conn) ExecContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Result, error) {
+	var res driver.Result
+	err := c.r.observe(ctx, query, func(ctx context.Context) error {
+		var err error
+		switch execer := c.Conn.(type) {
+		case driver.ExecerContext:
+			res, err = execer.ExecContext(ctx, query, args)
+		case driver.Execer:
+			var values []driver.Value
+			values, err = namedValuesToValues(args)
+			if err != nil {
+				return err
+			}
+			res, err = execer.Exec(query, values)
+		default:
+			err = driver.ErrSkip
+		}
+		return err
+	})
+	return res, err
+}
+
+func (c *conn) QueryContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Rows, error) {
+	var rows driver.Rows
+	err := c.r.observe(ctx, query, func(ctx context.Context) error {
+		var err error
+		switch queryer := c.Conn.(type) {
+		case driver.QueryerContext:
+			rows, err = queryer.QueryContext(ctx, query, args)
+		case driver.Queryer:
+			var values []driver.Value
+			values, err = namedValuesToValues(args)
+			if err != nil {
+				return err
+			}
+			rows, err = queryer.Query(query, values)
+		default:
+			err = driver.ErrSkip
+		}
+		return err
+	})
+	return rows, err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type stmt struct {
+	driver.Stmt
+	conn  *conn
+	query string
+}
+
+var (
+	_ driver.StmtExecContext   = (*stmt)(nil)
+	_ driver.StmtQueryContext  = (*stmt)(nil)
+	_ driver.NamedValueChecker = (*stmt)(nil)
+	_ driver.ColumnConverter   = (*stmt)(nil)
+)
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var res driver.Result
+	err := s.conn.r.observe(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		if sec, ok := s.Stmt.(driver.StmtExecContext); ok {
+			res, err = sec.ExecContext(ctx, args)
+			return err
+		}
+		var values []driver.Value
+		values, err = namedValuesToValues(args)
+		if err != nil {
+			return err
+		}
+		res, err = s.Stmt.Exec(values)
+		return err
+	})
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	err := s.conn.r.observe(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		if sqc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			rows, err = sqc.QueryContext(ctx, args)
+			return err
+		}
+		var values []driver.Value
+		values, err = namedValuesToValues(args)
+		if err != nil {
+			return err
+		}
+		rows, err = s.Stmt.Query(values)
+		return err
+	})
+	return rows, err
+}
+
+// CheckNamedValue uses the checker of the statement and then the one of
+// the connection, because database/sql only looks at the connection when
+// the statement doesn't implement driver.NamedValueChecker.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+func (s *stmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := s.Stmt.(driver.ColumnConverter); ok {
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+type wrappedTx struct {
+	driver.Tx
+	ctx context.Context
+	r   *reporter
+}
+
+func (tx *wrappedTx) Commit() error {
+	return tx.r.observe(tx.ctx, "COMMIT", func(context.Context) error {
+		return tx.Tx.Commit()
+	})
+}
+
+func (tx *wrappedTx) Rollback() error {
+	return tx.r.observe(tx.ctx, "ROLLBACK", func(context.Context) error {
+		return tx.Tx.Rollback()
+	})
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
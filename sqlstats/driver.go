@@ -0,0 +1,196 @@
+// Package sqlstats wraps database/sql drivers to report query stats to
+// Airbrake.
+//
+// Statements executed with a context that carries a gobrake.RouteMetric are
+// reported with the method and route of the request and are measured as db
+// spans of the metric. The route is reported as the metric has it when the
+// query runs. The middlewares of gobrake set route templates after the
+// request is handled and use the path normalized with gobrake.NormalizePath
+// until then:
+//
+//	db := sql.OpenDB(sqlstats.WrapConnector(connector, notifier))
+//	rows, err := db.QueryContext(req.Context(), "SELECT * FROM users")
+//
+// Queries of drivers from packages with mysql in the import path, e.g.
+// github.com/go-sql-driver/mysql, are normalized with
+// gobrake.QueryDialectMySQL.
+package sqlstats
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/airbrake/gobrake/v4"
+)
+
+const pkgPath = "github.com/airbrake/gobrake/v4/sqlstats."
+
+// Wrap returns a driver that reports stats of queries executed on
+// connections opened by d. It can be registered with sql.Register.
+func Wrap(d driver.Driver, notifier *gobrake.Notifier) driver.Driver {
+	drv := &wrappedDriver{
+		Driver: d,
+		r:      newReporter(d, notifier),
+	}
+	if _, ok := d.(driver.DriverContext); ok {
+		return &driverContext{drv}
+	}
+	return drv
+}
+
+// WrapConnector returns a connector that reports stats of queries executed
+// on connections created by c. It can be passed to sql.OpenDB.
+func WrapConnector(c driver.Connector, notifier *gobrake.Notifier) driver.Connector {
+	r := newReporter(c.Driver(), notifier)
+	return &connector{
+		Connector: c,
+		drv:       &wrappedDriver{Driver: c.Driver(), r: r},
+		r:         r,
+	}
+}
+
+type wrappedDriver struct {
+	driver.Driver
+	r *reporter
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(c, d.r), nil
+}
+
+type driverContext struct {
+	*wrappedDriver
+}
+
+func (d *driverContext) OpenConnector(name string) (driver.Connector, error) {
+	c, err := d.Driver.(driver.DriverContext).OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return &connector{
+		Connector: c,
+		drv:       d,
+		r:         d.r,
+	}, nil
+}
+
+type connector struct {
+	driver.Connector
+	drv driver.Driver
+	r   *reporter
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(conn, c.r), nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.drv
+}
+
+// Close closes the wrapped connector if it implements io.Closer.
+func (c *connector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// reporter measures statements and reports them to Airbrake.
+type reporter struct {
+	notifier *gobrake.Notifier
+	dialect  gobrake.QueryDialect
+}
+
+func newReporter(d driver.Driver, notifier *gobrake.Notifier) *reporter {
+	return &reporter{
+		notifier: notifier,
+		dialect:  driverDialect(d),
+	}
+}
+
+// driverDialect detects the SQL dialect by the package of the driver.
+func driverDialect(d driver.Driver) gobrake.QueryDialect {
+	t := reflect.TypeOf(d)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && strings.Contains(strings.ToLower(t.PkgPath()), "mysql") {
+		return gobrake.QueryDialectMySQL
+	}
+	return gobrake.QueryDialectDefault
+}
+
+// observe runs fn in a db span and reports query stats unless fn returns
+// driver.ErrSkip, which means that database/sql retries it in another way.
+func (r *reporter) observe(
+	ctx context.Context, query string, fn func(context.Context) error,
+) error {
+	ctx, span := gobrake.ContextMetric(ctx).Start(ctx, "db")
+	startTime := time.Now()
+	err := fn(ctx)
+	endTime := time.Now()
+	span.Finish()
+
+	if err == driver.ErrSkip {
+		return err
+	}
+
+	info := &gobrake.QueryInfo{
+		Query:     query,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Dialect:   r.dialect,
+	}
+	if metric := gobrake.ContextRouteMetric(ctx); metric != nil {
+		info.Method = metric.Method
+		info.Route = metric.Route
+	}
+	info.Func, info.File, info.Line = caller()
+	_ = r.notifier.Queries.Notify(ctx, info)
+
+	return err
+}
+
+// caller returns the first frame outside of database/sql and this package.
+func caller() (fn, file string, line int) {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPath) &&
+			!strings.HasPrefix(f.Function, "database/sql.") &&
+			!strings.HasPrefix(f.Function, "runtime.") {
+			return funcName(f.Function), f.File, f.Line
+		}
+		if !more {
+			return "", "", 0
+		}
+	}
+}
+
+// funcName strips the package path, e.g. example.com/models.fetchUser
+// becomes fetchUser.
+func funcName(name string) string {
+	if ind := strings.LastIndex(name, "/"); ind != -1 {
+		name = name[ind+1:]
+	}
+	if ind := strings.Index(name, "."); ind != -1 {
+		name = name[ind+1:]
+	}
+	return name
+}
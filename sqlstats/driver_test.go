@@ -0,0 +1,290 @@
+package sqlstats_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/airbrake/gobrake/v4"
+	httpbrake "github.com/airbrake/gobrake/v4/http"
+	"github.com/airbrake/gobrake/v4/sqlstats"
+)
+
+func TestSQLStats(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "sqlstats")
+}
+
+// fakeConnector creates connections that only implement the required
+// driver.Conn methods, or also ExecerContext and QueryerContext.
+type fakeConnector struct {
+	withContext bool
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.withContext {
+		return &fakeConnCtx{}, nil
+	}
+	return &fakeConn{}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+type fakeConnCtx struct {
+	fakeConn
+}
+
+func (c *fakeConnCtx) ExecContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConnCtx) QueryContext(
+	ctx context.Context, query string, args []driver.NamedValue,
+) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (fakeRows) Close() error {
+	return nil
+}
+
+func (fakeRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+type queryStat struct {
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	Query  string `json:"query"`
+	Func   string `json:"function"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Count  int    `json:"count"`
+}
+
+func fetchUser(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM users WHERE id = ?", 123)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+var _ = Describe("WrapConnector", func() {
+	var notifier *gobrake.Notifier
+	var connector *fakeConnector
+	var db *sql.DB
+
+	var mu sync.Mutex
+	var queries []queryStat
+
+	BeforeEach(func() {
+		queries = nil
+		connector = &fakeConnector{withContext: true}
+
+		handler := func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasSuffix(req.URL.Path, "config.json") {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+
+			b, err := ioutil.ReadAll(req.Body)
+			Expect(err).To(BeNil())
+
+			var out struct {
+				Queries []queryStat `json:"queries"`
+			}
+			err = json.Unmarshal(b, &out)
+			Expect(err).To(BeNil())
+
+			mu.Lock()
+			queries = append(queries, out.Queries...)
+			mu.Unlock()
+
+			w.WriteHeader(http.StatusCreated)
+		}
+		server := httptest.NewServer(http.HandlerFunc(handler))
+
+		notifier = gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+	})
+
+	JustBeforeEach(func() {
+		db = sql.OpenDB(sqlstats.WrapConnector(connector, notifier))
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).NotTo(HaveOccurred())
+		Expect(notifier.Close()).NotTo(HaveOccurred())
+	})
+
+	reported := func() []queryStat {
+		notifier.Queries.Flush()
+		mu.Lock()
+		defer mu.Unlock()
+		return queries
+	}
+
+	find := func(query string) *queryStat {
+		for _, q := range reported() {
+			if q.Query == query {
+				return &q
+			}
+		}
+		return nil
+	}
+
+	It("reports queries with route and caller", func() {
+		handler := httpbrake.New(notifier, nil).Handler(http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				Expect(fetchUser(req.Context(), db)).To(Succeed())
+			}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/123", nil))
+
+		q := find("SELECT * FROM users WHERE id = ?")
+		Expect(q).NotTo(BeNil())
+		Expect(q.Method).To(Equal("GET"))
+		Expect(q.Route).To(Equal("/users/:id"))
+		Expect(q.Func).To(Equal("fetchUser"))
+		Expect(q.File).To(HaveSuffix("sqlstats/driver_test.go"))
+		Expect(q.Line).NotTo(BeZero())
+		Expect(q.Count).To(Equal(1))
+	})
+
+	It("reports queries without route metric", func() {
+		_, err := db.ExecContext(context.Background(), "DELETE FROM users")
+		Expect(err).NotTo(HaveOccurred())
+
+		q := find("DELETE FROM users")
+		Expect(q).NotTo(BeNil())
+		Expect(q.Method).To(BeEmpty())
+		Expect(q.Route).To(BeEmpty())
+	})
+
+	It("reports transactions", func() {
+		tx, err := db.BeginTx(context.Background(), nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tx.Exec("UPDATE users SET name = ?", "bob")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.Commit()).To(Succeed())
+
+		Expect(find("BEGIN")).NotTo(BeNil())
+		Expect(find("UPDATE users SET name = ?")).NotTo(BeNil())
+		Expect(find("COMMIT")).NotTo(BeNil())
+	})
+
+	Context("when connection doesn't implement ExecerContext", func() {
+		BeforeEach(func() {
+			connector.withContext = false
+		})
+
+		It("reports prepared statements once", func() {
+			_, err := db.Exec("INSERT INTO users VALUES (?)", 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(find("PREPARE INSERT INTO users VALUES (?)")).NotTo(BeNil())
+			q := find("INSERT INTO users VALUES (?)")
+			Expect(q).NotTo(BeNil())
+			Expect(q.Count).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Wrap", func() {
+	It("wraps drivers registered with sql.Register", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		notifier := gobrake.NewNotifierWithOptions(&gobrake.NotifierOptions{
+			ProjectId:        1,
+			ProjectKey:       "key",
+			Host:             server.URL,
+			RemoteConfigHost: server.URL,
+		})
+		defer notifier.Close()
+
+		sql.Register("sqlstats-fake", sqlstats.Wrap(fakeDriver{}, notifier))
+		db, err := sql.Open("sqlstats-fake", "")
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		var id int
+		err = db.QueryRow("SELECT id FROM users").Scan(&id)
+		Expect(err).To(Equal(sql.ErrNoRows))
+	})
+})
@@ -2,25 +2,35 @@ package gobrake
 
 import (
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 )
 
+const defaultBacktraceDepth = 32
+
 // getBacktrace returns the stacktrace associated with e. If e is an
 // error from the errors package its stacktrace is extracted, otherwise
 // the current stacktrace is collected end returned.
-func getBacktrace(e interface{}, skip int) (string, []StackFrame) {
+func getBacktrace(e interface{}, skip int, opt *NotifierOptions) (string, []StackFrame) {
 	if err, ok := e.(stackTracer); ok {
-		return backtraceFromErrorWithStackTrace(err)
+		pkg, frames := backtraceFromErrorWithStackTrace(err, opt)
+		return pkg, limitFrames(frames, opt)
 	}
 
-	const depth = 32
-	var pcs [depth]uintptr
-	n := runtime.Callers(skip+1, pcs[:])
+	// PCs don't map to frames one to one because of inlining, so collect
+	// more of them and limit the number of frames afterwards.
+	depth := 2 * opt.BacktraceDepth
+	if opt.CollapseLibraryFrames || len(opt.SkipFrames) > 0 {
+		// Collect even more since some frames are dropped.
+		depth *= 2
+	}
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+1, pcs)
 	ff := runtime.CallersFrames(pcs[:n])
 
-	var firstPkg string
+	var firstPkg, appPkg string
 	frames := make([]StackFrame, 0)
 	for {
 		f, ok := ff.Next()
@@ -28,6 +38,10 @@ func getBacktrace(e interface{}, skip int) (string, []StackFrame) {
 			break
 		}
 
+		if skipFrame(f.Function, opt) {
+			continue
+		}
+
 		pkg, fn := splitPackageFuncName(f.Function)
 		if firstPkg == "" && pkg != "runtime" {
 			firstPkg = pkg
@@ -38,14 +52,62 @@ func getBacktrace(e interface{}, skip int) (string, []StackFrame) {
 			continue
 		}
 
+		kind := frameKind(pkg, f.File)
+		if appPkg == "" && kind == FrameApp {
+			appPkg = pkg
+		}
+
 		frames = append(frames, StackFrame{
-			File: f.File,
-			Line: f.Line,
-			Func: fn,
+			File:    f.File,
+			Line:    f.Line,
+			Func:    fn,
+			Kind:    kind,
+			Inlined: f.Func == nil,
 		})
 	}
 
-	return firstPkg, frames
+	if appPkg != "" {
+		firstPkg = appPkg
+	}
+	return firstPkg, limitFrames(frames, opt)
+}
+
+// skipFrame reports whether the function matches one of opt.SkipFrames.
+func skipFrame(function string, opt *NotifierOptions) bool {
+	for _, re := range opt.SkipFrames {
+		if re.MatchString(function) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitFrames collapses library frames if requested and limits the number
+// of frames to opt.BacktraceDepth.
+func limitFrames(frames []StackFrame, opt *NotifierOptions) []StackFrame {
+	if opt.CollapseLibraryFrames {
+		frames = collapseLibraryFrames(frames)
+	}
+	if len(frames) > opt.BacktraceDepth {
+		frames = frames[:opt.BacktraceDepth]
+	}
+	return frames
+}
+
+// goroutineID returns the ID of the current goroutine parsed from the
+// header of its stack trace, e.g. "goroutine 7 [running]:".
+func goroutineID() int {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	s := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+	if ind := strings.IndexByte(s, ' '); ind != -1 {
+		s = s[:ind]
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return id
 }
 
 func splitPackageFuncName(funcName string) (string, string) {
@@ -72,7 +134,7 @@ type stackTracer interface {
 }
 
 // backtraceFromErrorWithStackTrace extracts the stacktrace from e.
-func backtraceFromErrorWithStackTrace(e stackTracer) (string, []StackFrame) {
+func backtraceFromErrorWithStackTrace(e stackTracer, opt *NotifierOptions) (string, []StackFrame) {
 	stackTrace := e.StackTrace()
 	var pcs []uintptr
 	for _, f := range stackTrace {
@@ -80,7 +142,7 @@ func backtraceFromErrorWithStackTrace(e stackTracer) (string, []StackFrame) {
 	}
 
 	ff := runtime.CallersFrames(pcs)
-	var firstPkg string
+	var firstPkg, appPkg string
 	frames := make([]StackFrame, 0)
 	for {
 		f, ok := ff.Next()
@@ -88,17 +150,31 @@ func backtraceFromErrorWithStackTrace(e stackTracer) (string, []StackFrame) {
 			break
 		}
 
+		if skipFrame(f.Function, opt) {
+			continue
+		}
+
 		pkg, fn := splitPackageFuncName(f.Function)
 		if firstPkg == "" {
 			firstPkg = pkg
 		}
 
+		kind := frameKind(pkg, f.File)
+		if appPkg == "" && kind == FrameApp {
+			appPkg = pkg
+		}
+
 		frames = append(frames, StackFrame{
-			File: f.File,
-			Line: f.Line,
-			Func: fn,
+			File:    f.File,
+			Line:    f.Line,
+			Func:    fn,
+			Kind:    kind,
+			Inlined: f.Func == nil,
 		})
 	}
 
+	if appPkg != "" {
+		firstPkg = appPkg
+	}
 	return firstPkg, frames
 }
@@ -1,6 +1,8 @@
 package gobrake
 
 import (
+	"regexp"
+
 	testpkg1 "github.com/airbrake/gobrake/v4/internal/testpkg1"
 	testpkg2 "github.com/airbrake/gobrake/v4/internal/testpkg2"
 
@@ -33,8 +35,78 @@ var _ = Describe("backtraceFromErrorWithStackTrace", func() {
 			v, ok := test.err.(stackTracer)
 
 			Expect(ok).To(BeTrue())
-			packageName, _ := backtraceFromErrorWithStackTrace(v)
+			packageName, _ := backtraceFromErrorWithStackTrace(v, defaultNoticeOptions)
 			Expect(packageName).To(Equal(test.packageName))
 		}
 	})
 })
+
+// inlinedNotice is small enough to be inlined into its callers.
+func inlinedNotice(opt *NotifierOptions) *Notice {
+	return newNotice("hello", nil, 0, opt)
+}
+
+var _ = Describe("getBacktrace", func() {
+	var opt *NotifierOptions
+
+	BeforeEach(func() {
+		opt = new(NotifierOptions)
+	})
+
+	JustBeforeEach(func() {
+		opt.init()
+	})
+
+	Context("with BacktraceDepth", func() {
+		BeforeEach(func() {
+			opt.BacktraceDepth = 3
+		})
+
+		It("limits number of frames", func() {
+			notice := newNotice("hello", nil, 0, opt)
+			Expect(notice.Errors[0].Backtrace).To(HaveLen(3))
+		})
+	})
+
+	Context("with SkipFrames", func() {
+		BeforeEach(func() {
+			opt.SkipFrames = []*regexp.Regexp{
+				regexp.MustCompile(`^github\.com/onsi/`),
+				regexp.MustCompile(`/testpkg1\.Bar$`),
+			}
+		})
+
+		It("skips matching frames", func() {
+			notice := newNotice("hello", nil, 0, opt)
+			for _, frame := range notice.Errors[0].Backtrace {
+				Expect(frame.File).NotTo(ContainSubstring("github.com/onsi/"))
+			}
+		})
+
+		It("skips matching frames of errors with stack traces", func() {
+			notice := newNotice(testpkg1.Foo(), nil, 0, opt)
+			frame := notice.Errors[0].Backtrace[0]
+			Expect(frame.Func).To(Equal("Foo"))
+			Expect(notice.Context["component"]).To(Equal(
+				"github.com/airbrake/gobrake/v4/internal/testpkg1"))
+		})
+	})
+
+	It("marks inlined frames", func() {
+		notice := inlinedNotice(opt)
+		frame := notice.Errors[0].Backtrace[0]
+		Expect(frame.Func).To(Equal("inlinedNotice"))
+		Expect(frame.Inlined).To(BeTrue())
+	})
+
+	It("records goroutine id", func() {
+		done := make(chan *Notice)
+		go func() {
+			done <- newNotice("hello", nil, 0, opt)
+		}()
+		notice := <-done
+
+		Expect(notice.Context["goroutineId"]).To(BeNumerically(">", 1))
+		Expect(notice.Context["goroutineId"]).NotTo(Equal(goroutineID()))
+	})
+})
@@ -0,0 +1,33 @@
+package gobrake
+
+// NotifierStats holds counters of the notifier.
+type NotifierStats struct {
+	// Number of distinct keys that were folded into __other__ keys because
+	// their aggregator reached the APMMaxKeys limit. A key is counted once
+	// per flush window however many metrics it has.
+	RoutesFolded          uint64
+	RouteBreakdownsFolded uint64
+	QueriesFolded         uint64
+	QueuesFolded          uint64
+
+	// Number of source file lookups served from and missing in the code
+	// hunks cache. Both are zero when DisableCodeHunks is set.
+	CodeHunkCacheHits   uint64
+	CodeHunkCacheMisses uint64
+}
+
+// Stats returns counters accumulated since the notifier was created.
+func (n *Notifier) Stats() NotifierStats {
+	stats := NotifierStats{
+		RoutesFolded:          n.Routes.stats.limit.count(),
+		RouteBreakdownsFolded: n.Routes.breakdowns.limit.count(),
+		QueriesFolded:         n.Queries.limit.count(),
+		QueuesFolded:          n.Queues.limit.count(),
+	}
+	if n.codeHunks != nil {
+		cache := n.codeHunks.cache.Stats()
+		stats.CodeHunkCacheHits = cache.Hits
+		stats.CodeHunkCacheMisses = cache.Misses
+	}
+	return stats
+}